@@ -0,0 +1,305 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jonow/lsv"
+)
+
+// runCat prints the values of the files, each followed by a newline or, with
+// -0, a NUL character.
+func runCat(e *env, args []string) error {
+	flags, pf := e.flags()
+	nul := flags.Bool("0", false,
+		"end each value with a NUL character instead of a newline")
+	p, err := e.parse(flags, pf, args, 0)
+	if err != nil {
+		return err
+	}
+
+	end := byte('\n')
+	if *nul {
+		end = 0
+	}
+
+	w := bufio.NewWriter(e.stdout)
+	err = e.readFiles(flags.Args(), p, func(r *lsv.Reader) error {
+		for {
+			value, err := r.Read()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			_, _ = w.WriteString(value)
+			if err = w.WriteByte(end); err != nil {
+				return err
+			}
+		}
+	})
+
+	if flushErr := w.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// runCount prints the total number of values in the files.
+func runCount(e *env, args []string) error {
+	flags, pf := e.flags()
+	p, err := e.parse(flags, pf, args, 0)
+	if err != nil {
+		return err
+	}
+
+	var n int
+	err = e.readFiles(flags.Args(), p, func(r *lsv.Reader) error {
+		for {
+			_, err := r.Read()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			n++
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(e.stdout, n)
+	return err
+}
+
+// runValidate reports every file that cannot be parsed.
+func runValidate(e *env, args []string) error {
+	flags, pf := e.flags()
+	p, err := e.parse(flags, pf, args, 0)
+	if err != nil {
+		return err
+	}
+
+	names := flags.Args()
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	var invalid bool
+	for _, name := range names {
+		err = e.readFile(name, p, func(r *lsv.Reader) error {
+			_, err := r.ReadAll()
+			return err
+		})
+		if err != nil {
+			fmt.Fprintln(e.stderr, err)
+			invalid = true
+		}
+	}
+
+	if invalid {
+		return errReported
+	}
+	return nil
+}
+
+// runGet prints the value at index N.
+func runGet(e *env, args []string) error {
+	flags, pf := e.flags()
+	p, err := e.parse(flags, pf, args, 1)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() > 2 {
+		flags.Usage()
+		return errUsage
+	}
+
+	n, err := strconv.Atoi(flags.Arg(0))
+	if err != nil || n < 0 {
+		fmt.Fprintf(e.stderr, "lsv get: invalid index %q\n", flags.Arg(0))
+		return errUsage
+	}
+
+	var i int
+	err = e.readFiles(flags.Args()[1:], p, func(r *lsv.Reader) error {
+		for ; ; i++ {
+			value, err := r.Read()
+			if err == io.EOF {
+				return fmt.Errorf("index %d out of range with %d values", n, i)
+			} else if err != nil {
+				return err
+			} else if i == n {
+				_, err = fmt.Fprintln(e.stdout, value)
+				return err
+			}
+		}
+	})
+	return err
+}
+
+// runAppend adds the values, with an optional inline comment, to the end of
+// the file.
+func runAppend(e *env, args []string) error {
+	flags, pf := e.flags()
+	comment := flags.String("c", "", "inline `comment` for each value")
+	p, err := e.parse(flags, pf, args, 2)
+	if err != nil {
+		return err
+	}
+
+	return editFile(flags.Arg(0), p, func(d *lsv.Document) error {
+		for _, value := range flags.Args()[1:] {
+			d.Append(lsv.NewValueNode(value, *comment))
+		}
+		return nil
+	})
+}
+
+// runRemove removes every occurrence of the values from the file. Nothing is
+// changed if any of the values are not in the file.
+func runRemove(e *env, args []string) error {
+	flags, pf := e.flags()
+	p, err := e.parse(flags, pf, args, 2)
+	if err != nil {
+		return err
+	}
+
+	return editFile(flags.Arg(0), p, func(d *lsv.Document) error {
+		for _, value := range flags.Args()[1:] {
+			i := d.Find(value)
+			if i == -1 {
+				return fmt.Errorf(
+					"value %q not found in %s", value, flags.Arg(0))
+			}
+			for ; i > -1; i = d.Find(value) {
+				d.Delete(i)
+			}
+		}
+		return nil
+	})
+}
+
+// editFile parses the named file into a Document, calls edit, and writes the
+// changed Document back to the file. A file that does not exist is treated as
+// empty and is created. The file is not written if any error occurs.
+func editFile(name string, p lsv.Parameters,
+	edit func(*lsv.Document) error) error {
+	perm := fs.FileMode(0666)
+	src, err := os.ReadFile(name)
+	if err == nil {
+		info, err := os.Stat(name)
+		if err != nil {
+			return err
+		}
+		perm = info.Mode().Perm()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	d, err := lsv.ParseDocument(bytes.NewReader(src), p)
+	if err != nil {
+		if pe := (*lsv.ParseError)(nil); errors.As(err, &pe) {
+			pe.File = name
+		}
+		return err
+	}
+
+	if err = edit(d); err != nil {
+		return err
+	}
+
+	var buff bytes.Buffer
+	if _, err = d.WriteTo(&buff); err != nil {
+		return err
+	}
+	return os.WriteFile(name, buff.Bytes(), perm)
+}
+
+// lintRules contains the IDs of all lint rules.
+var lintRules = []string{
+	lsv.RuleParseError,
+	lsv.RuleDuplicate,
+	lsv.RuleUnnecessaryQuote,
+	lsv.RuleURLFragment,
+	lsv.RuleTrailingEscape,
+	lsv.RuleMixedLineEndings,
+	lsv.RuleStrayRaw,
+	lsv.RuleWhitespaceValue,
+}
+
+// runVet prints the diagnostics found by lsv.Lint in the files, except for
+// those of disabled rules.
+func runVet(e *env, args []string) error {
+	flags, pf := e.flags()
+	disable := flags.String("disable", "",
+		"comma-separated list of `rules` not to report")
+	p, err := e.parse(flags, pf, args, 0)
+	if err != nil {
+		return err
+	}
+
+	disabled := make(map[string]bool)
+	if *disable != "" {
+		for _, rule := range strings.Split(*disable, ",") {
+			if !isLintRule(rule) {
+				fmt.Fprintf(e.stderr, "lsv vet: unknown rule %q\n", rule)
+				return errUsage
+			}
+			disabled[rule] = true
+		}
+	}
+
+	names := flags.Args()
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	var found bool
+	for _, name := range names {
+		f, name, err := e.open(name)
+		if err != nil {
+			return err
+		}
+		diags := lsv.Lint(f, p)
+		f.Close()
+
+		for _, d := range diags {
+			if !disabled[d.Rule] {
+				fmt.Fprintf(e.stdout, "%s:%s\n", name, d)
+				found = true
+			}
+		}
+	}
+
+	if found {
+		return errReported
+	}
+	return nil
+}
+
+// isLintRule returns true if the rule is the ID of a lint rule.
+func isLintRule(rule string) bool {
+	for _, r := range lintRules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
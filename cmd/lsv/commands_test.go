@@ -0,0 +1,219 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testList = "# Header\na  # First\n\"b\nc\"\n\n  d\n"
+
+// Tests that the read-only commands produce the expected output.
+func TestCommands(t *testing.T) {
+	type test struct {
+		Name   string
+		Args   []string
+		Stdin  string
+		Output string
+	}
+
+	path := writeFile(t, "list.lsv", testList)
+	tests := []test{
+		{"Cat", []string{"cat"}, testList, "a\nb\nc\nd\n"},
+		{"CatNUL", []string{"cat", "-0", path}, "", "a\x00b\nc\x00d\x00"},
+		{"CatMultiple", []string{"cat", path, "-", path}, "e\n",
+			"a\nb\nc\nd\ne\na\nb\nc\nd\n"},
+		{"CatParameters", []string{"cat", "--comment=;", "--no-trim"},
+			" a ;b\n#c\n", " a\n#c\n"},
+		{"Count", []string{"count", path}, "", "3\n"},
+		{"CountMultiple", []string{"count", path, "-"}, "e\nf\n", "5\n"},
+		{"CountEmpty", []string{"count"}, "", "0\n"},
+		{"Validate", []string{"validate", path, "-"}, "a\n", ""},
+		{"GetFirst", []string{"get", "0", path}, "", "a\n"},
+		{"GetMultiLine", []string{"get", "1", path}, "", "b\nc\n"},
+		{"GetStdin", []string{"get", "2"}, testList, "d\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			res := runLSV(tt.Stdin, tt.Args...)
+			if res.code != 0 {
+				t.Fatalf("Unexpected exit code %d: %s", res.code, res.stderr)
+			}
+			if res.stdout != tt.Output {
+				t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+					tt.Output, res.stdout)
+			}
+		})
+	}
+}
+
+// Tests that the commands report errors with exit status 1.
+func TestCommands_Error(t *testing.T) {
+	type test struct {
+		Name  string
+		Args  []string
+		Stdin string
+		Error string
+	}
+
+	path := writeFile(t, "list.lsv", testList)
+	bad := writeFile(t, "bad.lsv", "a\n\"b\n")
+	missing := filepath.Join(t.TempDir(), "missing.lsv")
+	tests := []test{
+		{"CatParseError", []string{"cat", bad}, "",
+			"lsv cat: " + bad + ": value on line 2"},
+		{"CatMissing", []string{"cat", missing}, "", "missing.lsv"},
+		{"CountParseError", []string{"count", "-"}, "\"a", "line 1"},
+		{"ValidateParseError", []string{"validate", path, bad, "-"}, "\"",
+			bad + ": value on line 2; parse error on line 3, column 1: " +
+				"raw literal not closed\n<standard input>: parse error on " +
+				"line 1, column 2: raw literal not closed\n"},
+		{"GetOutOfRange", []string{"get", "3", path}, "",
+			"index 3 out of range with 3 values"},
+		{"RemoveMissingValue", []string{"remove", path, "a", "e"}, "",
+			"value \"e\" not found"},
+		{"AppendParseError", []string{"append", bad, "c"}, "",
+			bad + ": value on line 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			res := runLSV(tt.Stdin, tt.Args...)
+			if res.code != 1 {
+				t.Errorf("Unexpected exit code.\nexpected: %d\nreceived: %d",
+					1, res.code)
+			}
+			if !strings.Contains(res.stderr, tt.Error) {
+				t.Errorf("Unexpected error.\nexpected: %q\nreceived: %q",
+					tt.Error, res.stderr)
+			}
+		})
+	}
+
+	// Files must be unchanged after failed edits
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %+v", path, err)
+	}
+	if string(data) != testList {
+		t.Errorf("File changed after failed edit.\nexpected: %q\nreceived: %q",
+			testList, data)
+	}
+}
+
+// Tests that append and remove edit the file while keeping its comments and
+// formatting.
+func TestCommands_Edit(t *testing.T) {
+	type test struct {
+		Name   string
+		Input  string
+		Args   []string
+		Output string
+	}
+
+	// FILE is replaced with the path to the file
+	tests := []test{
+		{"Append", testList, []string{"append", "FILE", "e", " f"},
+			testList + "e\n\" f\"\n"},
+		{"AppendComment", "a", []string{"append", "-c", "New", "FILE", "b#"},
+			"a\nb\\#\t# New\n"},
+		{"AppendEmptyComment", "a\n",
+			[]string{"append", "-c", "note", "FILE", ""}, "a\n\"\"\t# note\n"},
+		{"Remove", testList, []string{"remove", "FILE", "b\nc", "d"},
+			"# Header\na  # First\n\n"},
+		{"RemoveAll", "a\nb\na\n", []string{"remove", "FILE", "a"}, "b\n"},
+		{"Parameters", "a ;1\n",
+			[]string{"append", "--comment=;", "--escape=/", "FILE", "b;"},
+			"a ;1\nb/;\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			path := writeFile(t, "list.lsv", tt.Input)
+			args := make([]string, len(tt.Args))
+			for i, arg := range tt.Args {
+				args[i] = strings.ReplaceAll(arg, "FILE", path)
+			}
+
+			res := runLSV("", args...)
+			if res.code != 0 {
+				t.Fatalf("Unexpected exit code %d: %s", res.code, res.stderr)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read %s: %+v", path, err)
+			}
+			if string(data) != tt.Output {
+				t.Errorf("Unexpected file contents."+
+					"\nexpected: %q\nreceived: %q", tt.Output, data)
+			}
+		})
+	}
+}
+
+// Tests that append creates a file that does not exist.
+func TestCommands_AppendCreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.lsv")
+	res := runLSV("", "append", path, "a", "")
+	if res.code != 0 {
+		t.Fatalf("Unexpected exit code %d: %s", res.code, res.stderr)
+	}
+
+	expected := "a\n\"\"\n"
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %+v", path, err)
+	}
+	if string(data) != expected {
+		t.Errorf("Unexpected file contents.\nexpected: %q\nreceived: %q",
+			expected, data)
+	}
+}
+
+// Tests that vet reports the diagnostics of enabled rules with the file name.
+func TestCommands_Vet(t *testing.T) {
+	type test struct {
+		Name   string
+		Args   []string
+		Code   int
+		Output string
+	}
+
+	src := "a\n\"b\"\na\n"
+	path := writeFile(t, "list.lsv", src)
+	tests := []test{
+		{"All", []string{"vet", path}, 1,
+			path + ":2:1: value does not need quotes (unnecessary-quote)\n" +
+				path + ":3:1: duplicate of value on line 1 (duplicate)\n"},
+		{"Stdin", []string{"vet", "--disable=duplicate"}, 1,
+			"<standard input>:2:1: value does not need quotes " +
+				"(unnecessary-quote)\n"},
+		{"DisableAll", []string{"vet", "--disable",
+			"duplicate,unnecessary-quote", path}, 0, ""},
+		{"UnknownRule", []string{"vet", "--disable=unknown", path}, 2, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			res := runLSV(src, tt.Args...)
+			if res.code != tt.Code {
+				t.Errorf("Unexpected exit code.\nexpected: %d\nreceived: %d",
+					tt.Code, res.code)
+			}
+			if res.stdout != tt.Output {
+				t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+					tt.Output, res.stdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+// Lsv reads and edits LSV files.
+//
+// Usage:
+//
+//	lsv <command> [flags] [arguments]
+//
+// The commands are:
+//
+//	cat [-0] [file ...]
+//		Print the decoded values one per line or, with -0, each followed by
+//		a NUL character.
+//	count [file ...]
+//		Print the number of values.
+//	validate [file ...]
+//		Report every file that cannot be parsed and exit with status 1 if
+//		there are any.
+//	get N [file]
+//		Print the value at index N, starting at 0.
+//	append [-c comment] file value ...
+//		Add the values to the end of the file, creating it if it does not
+//		exist.
+//	remove file value ...
+//		Remove every occurrence of the values from the file.
+//	vet [-disable rule,...] [file ...]
+//		Report values and lines that are valid but likely to be mistakes and
+//		exit with status 1 if there are any. Each report ends with the ID of
+//		the rule that found it; the rules in the comma-separated -disable list
+//		are not reported. See lsv.Lint for the rules.
+//
+// Commands that read files read the standard input when no file or "-" is
+// given. Commands that edit a file keep its comments, blank lines, and
+// formatting.
+//
+// Every command accepts the flags
+//
+//	--comment, --raw, --escape
+//		Set the comment, raw string literal, and escape characters.
+//	--no-trim
+//		Keep the leading whitespace of unquoted values.
+//	--strict
+//		Use the strict escape grammar described by lsv.Parameters.
+//	--escape-sequences
+//		Decode C-style escape sequences, such as \t, in quoted values.
+//	--sections
+//		Read lines starting with [ as section headers.
+//
+// Lsv exits with status 1 if a command fails and 2 if it is used incorrectly.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jonow/lsv"
+	"github.com/jonow/lsv/internal/paramflag"
+)
+
+// errUsage is returned when a command is used incorrectly. The usage is
+// printed before it is returned.
+var errUsage = errors.New("usage")
+
+// errReported is returned when a command fails and has already reported why.
+var errReported = errors.New("failed")
+
+// command is an lsv subcommand.
+type command struct {
+	name  string
+	args  string
+	short string
+	run   func(e *env, args []string) error
+}
+
+// commands contains all subcommands in the order they are listed in the usage.
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{"cat", "[-0] [file ...]", "print values", runCat},
+		{"count", "[file ...]", "print the number of values", runCount},
+		{"validate", "[file ...]", "check that files can be parsed",
+			runValidate},
+		{"get", "N [file]", "print the value at index N", runGet},
+		{"append", "[-c comment] file value ...",
+			"add values to the end of a file", runAppend},
+		{"remove", "file value ...", "remove values from a file", runRemove},
+		{"vet", "[-disable rule,...] [file ...]",
+			"report suspicious constructs", runVet},
+	}
+}
+
+// env is the environment a command runs in.
+type env struct {
+	cmd    *command
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs lsv with the arguments and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		usage(stderr)
+		return 2
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+
+		e := &env{cmd, stdin, stdout, stderr}
+		err := cmd.run(e, args[1:])
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+			return 2
+		case errors.Is(err, errReported):
+			return 1
+		default:
+			fmt.Fprintf(stderr, "lsv %s: %v\n", cmd.name, err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(stderr, "lsv: unknown command %q\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+// usage prints the list of commands to w.
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "usage: lsv <command> [flags] [arguments]\n\n")
+	fmt.Fprintf(w, "The commands are:\n\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "\t%-10s %s\n", cmd.name, cmd.short)
+	}
+	fmt.Fprintf(w, "\nRun 'lsv <command> -h' for the flags of a command.\n")
+}
+
+// flags returns a new FlagSet for the command with the Parameters flags
+// registered.
+func (e *env) flags() (*flag.FlagSet, *paramflag.Flags) {
+	fs := flag.NewFlagSet("lsv "+e.cmd.name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "usage: lsv %s %s\n", e.cmd.name, e.cmd.args)
+		fs.PrintDefaults()
+	}
+	return fs, paramflag.Register(fs)
+}
+
+// parse parses the arguments with the FlagSet and returns the Parameters. It
+// returns errUsage if the flags are invalid or the number of remaining
+// arguments is less than min.
+func (e *env) parse(fs *flag.FlagSet, pf *paramflag.Flags, args []string,
+	min int) (lsv.Parameters, error) {
+	if err := fs.Parse(args); err != nil {
+		return lsv.Parameters{}, errUsage
+	}
+	if fs.NArg() < min {
+		fs.Usage()
+		return lsv.Parameters{}, errUsage
+	}
+
+	p, err := pf.Parameters()
+	if err != nil {
+		fmt.Fprintf(e.stderr, "lsv %s: %v\n", e.cmd.name, err)
+		return p, errUsage
+	}
+	return p, nil
+}
+
+// readFiles calls fn with a Reader for each file in order. The standard input
+// is read if there are no files or a file is "-". It stops at the first error
+// returned by fn.
+func (e *env) readFiles(names []string, p lsv.Parameters,
+	fn func(r *lsv.Reader) error) error {
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	for _, name := range names {
+		err := e.readFile(name, p, fn)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFile calls fn with a Reader for the named file or, if the name is "-",
+// the standard input.
+func (e *env) readFile(name string, p lsv.Parameters,
+	fn func(r *lsv.Reader) error) error {
+	f, name, err := e.open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := lsv.NewCustomReader(f, p)
+	r.FileName = name
+	return fn(r)
+}
+
+// open opens the named file or, if the name is "-", the standard input. It
+// returns the name to use for the file in messages.
+func (e *env) open(name string) (io.ReadCloser, string, error) {
+	if name == "-" {
+		return io.NopCloser(e.stdin), "<standard input>", nil
+	}
+
+	f, err := os.Open(name)
+	return f, name, err
+}
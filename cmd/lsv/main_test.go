@@ -0,0 +1,87 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// result is the output of a single run of lsv.
+type result struct {
+	code           int
+	stdout, stderr string
+}
+
+// runLSV runs lsv with the arguments and standard input.
+func runLSV(stdin string, args ...string) result {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return result{code, stdout.String(), stderr.String()}
+}
+
+// writeFile writes the contents to a file in a new temporary directory and
+// returns its path.
+func writeFile(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %+v", name, err)
+	}
+	return path
+}
+
+// Tests that lsv prints the usage and exits with status 2 when no command or
+// an unknown command is given.
+func TestRun_Usage(t *testing.T) {
+	for _, args := range [][]string{nil, {"help"}, {"unknown"}} {
+		res := runLSV("", args...)
+		if res.code != 2 {
+			t.Errorf("Unexpected exit code for %q."+
+				"\nexpected: %d\nreceived: %d", args, 2, res.code)
+		}
+		if !strings.Contains(res.stderr, "usage: lsv <command>") {
+			t.Errorf("Usage not printed for %q: %q", args, res.stderr)
+		}
+		for _, cmd := range commands {
+			if !strings.Contains(res.stderr, cmd.name) {
+				t.Errorf("Command %q missing from usage for %q.",
+					cmd.name, args)
+			}
+		}
+	}
+}
+
+// Tests that a command given invalid flags or too few arguments prints its
+// usage and exits with status 2.
+func TestRun_CommandUsage(t *testing.T) {
+	tests := [][]string{
+		{"cat", "-x"},
+		{"count", "--comment=ab"},
+		{"get"},
+		{"get", "a"},
+		{"get", "-1"},
+		{"get", "1", "a", "b"},
+		{"append", "file"},
+		{"remove", "file"},
+		{"validate", "--raw=#"},
+	}
+
+	for _, args := range tests {
+		res := runLSV("", args...)
+		if res.code != 2 {
+			t.Errorf("Unexpected exit code for %q."+
+				"\nexpected: %d\nreceived: %d", args, 2, res.code)
+		}
+		if res.stderr == "" {
+			t.Errorf("No usage printed for %q.", args)
+		}
+	}
+}
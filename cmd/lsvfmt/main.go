@@ -0,0 +1,197 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+// Lsvfmt formats LSV files.
+//
+// Usage:
+//
+//	lsvfmt [flags] [path ...]
+//
+// Without a path, it formats the standard input. Given a file, it formats that
+// file; given a directory, it formats all .lsv files in that directory,
+// recursively. By default, lsvfmt prints the formatted sources to standard
+// output.
+//
+// The flags are:
+//
+//	-d
+//		Do not print formatted sources to standard output. If a file's
+//		formatting is different from lsvfmt's, print diffs to standard output.
+//	-l
+//		Do not print formatted sources to standard output. If a file's
+//		formatting is different from lsvfmt's, print its name to standard
+//		output.
+//	-w
+//		Do not print formatted sources to standard output. If a file's
+//		formatting is different from lsvfmt's, overwrite it with lsvfmt's
+//		version.
+//	--comment, --raw, --escape
+//		Set the comment, raw string literal, and escape characters.
+//	--no-trim
+//		Keep the leading whitespace of unquoted values.
+//	--strict
+//		Use the strict escape grammar described by lsv.Parameters.
+//	--escape-sequences
+//		Decode C-style escape sequences, such as \t, in quoted values.
+//	--sections
+//		Read lines starting with [ as section headers.
+//
+// The formatting is described by lsv.Format.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/jonow/lsv"
+	"github.com/jonow/lsv/internal/diff"
+	"github.com/jonow/lsv/internal/paramflag"
+)
+
+// errStdinWrite is returned when -w is used without a path.
+var errStdinWrite = errors.New("cannot use -w with standard input")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// formatter formats files according to the command-line flags.
+type formatter struct {
+	params            lsv.Parameters
+	list, write, diff bool
+	stdout            io.Writer
+}
+
+// run runs lsvfmt with the arguments and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lsvfmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: lsvfmt [flags] [path ...]\n")
+		flags.PrintDefaults()
+	}
+
+	f := &formatter{stdout: stdout}
+	flags.BoolVar(&f.list, "l", false,
+		"list files whose formatting differs from lsvfmt's")
+	flags.BoolVar(&f.write, "w", false,
+		"write result to (source) file instead of stdout")
+	flags.BoolVar(&f.diff, "d", false,
+		"display diffs instead of rewriting files")
+	pf := paramflag.Register(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var err error
+	if f.params, err = pf.Parameters(); err != nil {
+		fmt.Fprintf(stderr, "lsvfmt: %v\n", err)
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		if err = f.formatFile("<standard input>", stdin, false); err != nil {
+			fmt.Fprintf(stderr, "lsvfmt: %v\n", err)
+			return 2
+		}
+		return 0
+	}
+
+	exit := 0
+	for _, root := range flags.Args() {
+		// Files named explicitly are formatted whatever their extension
+		err = filepath.WalkDir(root,
+			func(path string, d fs.DirEntry, err error) error {
+				if err == nil && d.IsDir() {
+					return nil
+				}
+				if err == nil && (path == root || isLSVFile(d)) {
+					err = f.formatPath(path)
+				}
+				if err != nil {
+					fmt.Fprintf(stderr, "%v\n", err)
+					exit = 2
+				}
+				return nil
+			})
+		if err != nil {
+			fmt.Fprintf(stderr, "%v\n", err)
+			exit = 2
+		}
+	}
+
+	return exit
+}
+
+// isLSVFile returns true if the entry is a visible .lsv file.
+func isLSVFile(d fs.DirEntry) bool {
+	name := d.Name()
+	return !d.IsDir() && name[0] != '.' && filepath.Ext(name) == ".lsv"
+}
+
+// formatPath formats the file at the path.
+func (f *formatter) formatPath(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return f.formatFile(path, file, true)
+}
+
+// formatFile formats the source read from in, which is named name. If isFile
+// is false, the source is the standard input and it cannot be overwritten.
+func (f *formatter) formatFile(name string, in io.Reader, isFile bool) error {
+	if f.write && !isFile {
+		return errStdinWrite
+	}
+
+	src, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	out, err := lsv.Format(src, f.params)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	if !bytes.Equal(src, out) {
+		if f.list {
+			fmt.Fprintln(f.stdout, name)
+		}
+		if f.write {
+			info, err := os.Stat(name)
+			if err != nil {
+				return err
+			}
+			err = os.WriteFile(name, out, info.Mode().Perm())
+			if err != nil {
+				return err
+			}
+		}
+		if f.diff {
+			_, err = f.stdout.Write(
+				diff.Diff(name+".orig", src, name, out))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if !f.list && !f.write && !f.diff {
+		_, err = f.stdout.Write(out)
+	}
+	return err
+}
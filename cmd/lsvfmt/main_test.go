@@ -0,0 +1,171 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	unformatted = "  a   # Comment\n\"b\"\n\n\n"
+	formatted   = "a\t# Comment\nb\n"
+)
+
+// writeFiles creates the files in a new temporary directory and returns its
+// path.
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to make directory for %s: %+v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %+v", name, err)
+		}
+	}
+	return dir
+}
+
+// Tests that lsvfmt formats the standard input to the standard output.
+func TestRun_Stdin(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run(nil, strings.NewReader(unformatted), &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Unexpected exit code %d: %s", code, stderr.String())
+	}
+
+	if stdout.String() != formatted {
+		t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+			formatted, stdout.String())
+	}
+}
+
+// Tests that -l lists only the .lsv files in a directory that are not
+// formatted.
+func TestRun_List(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.lsv":        unformatted,
+		"b.lsv":        formatted,
+		"sub/c.lsv":    unformatted,
+		"sub/d.txt":    unformatted,
+		"sub/.hid.lsv": unformatted,
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-l", dir}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Unexpected exit code %d: %s", code, stderr.String())
+	}
+
+	expected := filepath.Join(dir, "a.lsv") + "\n" +
+		filepath.Join(dir, "sub", "c.lsv") + "\n"
+	if stdout.String() != expected {
+		t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+			expected, stdout.String())
+	}
+}
+
+// Tests that -w overwrites an unformatted file with its formatted version.
+func TestRun_Write(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"list.txt": unformatted})
+	path := filepath.Join(dir, "list.txt")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-w", path}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Unexpected exit code %d: %s", code, stderr.String())
+	}
+
+	if stdout.Len() != 0 {
+		t.Errorf("Unexpected output: %q", stdout.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %+v", err)
+	}
+	if string(data) != formatted {
+		t.Errorf("Unexpected file contents.\nexpected: %q\nreceived: %q",
+			formatted, data)
+	}
+}
+
+// Tests that -d prints a diff of an unformatted file.
+func TestRun_Diff(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.lsv": unformatted})
+	path := filepath.Join(dir, "a.lsv")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-d", path}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Unexpected exit code %d: %s", code, stderr.String())
+	}
+
+	expected := "--- " + path + ".orig\n+++ " + path + "\n" +
+		"@@ -1,4 +1,2 @@\n-  a   # Comment\n-\"b\"\n-\n-\n+a\t# Comment\n+b\n"
+	if stdout.String() != expected {
+		t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+			expected, stdout.String())
+	}
+}
+
+// Tests that lsvfmt uses the Parameters flags.
+func TestRun_Parameters(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--comment=;", "--raw='"},
+		strings.NewReader("'a' ;1\n'#'\n"), &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Unexpected exit code %d: %s", code, stderr.String())
+	}
+
+	expected := "a\t; 1\n#\n"
+	if stdout.String() != expected {
+		t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+			expected, stdout.String())
+	}
+}
+
+// Tests that lsvfmt reports errors with a non-zero exit code.
+func TestRun_Error(t *testing.T) {
+	type test struct {
+		Name  string
+		Args  []string
+		Stdin string
+		Error string
+	}
+
+	dir := writeFiles(t, map[string]string{"bad.lsv": "a\n\"b\n"})
+	tests := []test{
+		{"ParseError", []string{filepath.Join(dir, "bad.lsv")}, "",
+			"bad.lsv: value on line 2"},
+		{"Missing", []string{filepath.Join(dir, "missing.lsv")}, "",
+			"missing.lsv"},
+		{"StdinWrite", []string{"-w"}, "a\n", errStdinWrite.Error()},
+		{"InvalidParams", []string{"--raw=#"}, "a\n", "invalid"},
+		{"InvalidFlag", []string{"-x"}, "", "usage: lsvfmt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.Args, strings.NewReader(tt.Stdin), &stdout, &stderr)
+			if code != 2 {
+				t.Errorf("Unexpected exit code.\nexpected: %d\nreceived: %d",
+					2, code)
+			}
+			if !strings.Contains(stderr.String(), tt.Error) {
+				t.Errorf("Unexpected error.\nexpected: %q\nreceived: %q",
+					tt.Error, stderr.String())
+			}
+		})
+	}
+}
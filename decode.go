@@ -0,0 +1,169 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// ErrDecodeTarget is returned by Decode when v is not a non-nil pointer to a
+// slice of a supported type.
+var ErrDecodeTarget = errors.New("decode target must be a non-nil pointer " +
+	"to a slice of a supported type")
+
+// DecodeError is returned by Decode when a value cannot be converted to the
+// element type of the slice. Line numbers are 1-indexed.
+type DecodeError struct {
+	File  string       // Name of the file being read, if known
+	Line  int          // Line where the value starts
+	Value string       // The value that could not be converted
+	Type  reflect.Type // The element type it was converted to
+	Err   error        // The conversion error
+}
+
+func (e *DecodeError) Error() string {
+	var file string
+	if e.File != "" {
+		file = e.File + ": "
+	}
+	return fmt.Sprintf("%sline %d: cannot decode %q as %v: %v",
+		file, e.Line, e.Value, e.Type, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+var (
+	unmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType    = reflect.TypeOf(time.Duration(0))
+)
+
+// Decode reads all values from r using the default Parameters and stores them,
+// converted to the element type, in the slice pointed to by v. See
+// [Reader.Decode] for the supported types.
+func Decode(r io.Reader, v any) error {
+	return NewReader(r).Decode(v)
+}
+
+// Decode reads all remaining values and stores them, converted to the element
+// type, in the slice pointed to by v, replacing its contents. v must be a
+// pointer to a slice of strings, bools, integers, floats, [time.Duration]
+// values, or of any type T where *T implements [encoding.TextUnmarshaler],
+// such as [time.Time] or [net/netip.Prefix]. Durations are parsed with
+// [time.ParseDuration].
+//
+// If a value cannot be converted, Decode returns a DecodeError reporting the
+// line of the value. If v is not a supported type, it returns ErrDecodeTarget.
+// On error, v is not modified.
+func (r *Reader) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() ||
+		rv.Elem().Kind() != reflect.Slice {
+		return ErrDecodeTarget
+	}
+	slice := rv.Elem()
+	elem := slice.Type().Elem()
+	convert := converter(elem)
+	if convert == nil {
+		return ErrDecodeTarget
+	}
+
+	out := reflect.MakeSlice(slice.Type(), 0, 0)
+	for {
+		rec, err := r.ReadRecord()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		e := reflect.New(elem).Elem()
+		if err = convert(rec.Value, e); err != nil {
+			start, _ := r.ValueRange()
+			return &DecodeError{
+				File:  r.FileName,
+				Line:  start.Line,
+				Value: rec.Value,
+				Type:  elem,
+				Err:   err,
+			}
+		}
+		out = reflect.Append(out, e)
+	}
+
+	slice.Set(out)
+	return nil
+}
+
+// converter returns a function that converts a value to the type t and stores
+// it in e. It returns nil if the type is not supported.
+func converter(t reflect.Type) func(value string, e reflect.Value) error {
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return func(value string, e reflect.Value) error {
+			u := e.Addr().Interface().(encoding.TextUnmarshaler)
+			return u.UnmarshalText([]byte(value))
+		}
+	} else if t == durationType {
+		return func(value string, e reflect.Value) error {
+			d, err := time.ParseDuration(value)
+			e.SetInt(int64(d))
+			return err
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return func(value string, e reflect.Value) error {
+			e.SetString(value)
+			return nil
+		}
+	case reflect.Bool:
+		return func(value string, e reflect.Value) error {
+			b, err := strconv.ParseBool(value)
+			e.SetBool(b)
+			return numError(err)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return func(value string, e reflect.Value) error {
+			i, err := strconv.ParseInt(value, 10, t.Bits())
+			e.SetInt(i)
+			return numError(err)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return func(value string, e reflect.Value) error {
+			u, err := strconv.ParseUint(value, 10, t.Bits())
+			e.SetUint(u)
+			return numError(err)
+		}
+	case reflect.Float32, reflect.Float64:
+		return func(value string, e reflect.Value) error {
+			f, err := strconv.ParseFloat(value, t.Bits())
+			e.SetFloat(f)
+			return numError(err)
+		}
+	}
+	return nil
+}
+
+// numError returns the underlying error of a [strconv.NumError], such as
+// [strconv.ErrSyntax], since the DecodeError already includes the value.
+func numError(err error) error {
+	var ne *strconv.NumError
+	if errors.As(err, &ne) {
+		return ne.Err
+	}
+	return err
+}
@@ -0,0 +1,154 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests that Decode converts the values to each supported element type.
+func TestDecode(t *testing.T) {
+	type test struct {
+		Name     string
+		Input    string
+		V        any
+		Expected any
+	}
+
+	type name string
+
+	tests := []test{
+		{"String", "a\n\"  b  \"\n", &[]string{}, &[]string{"a", "  b  "}},
+		{"NamedString", "a\n", &[]name{}, &[]name{"a"}},
+		{"Int", "1 # one\n-2\n\n# three\n+3\n", &[]int{},
+			&[]int{1, -2, 3}},
+		{"Int8", "127\n-128\n", &[]int8{}, &[]int8{127, -128}},
+		{"Uint", "0\n18446744073709551615\n", &[]uint64{},
+			&[]uint64{0, 18446744073709551615}},
+		{"Float64", "1.5\n-2e3\n", &[]float64{}, &[]float64{1.5, -2e3}},
+		{"Float32", "0.25\n", &[]float32{}, &[]float32{0.25}},
+		{"Bool", "true\nF\n1\n", &[]bool{}, &[]bool{true, false, true}},
+		{"Duration", "1h30m\n250ms\n", &[]time.Duration{},
+			&[]time.Duration{90 * time.Minute, 250 * time.Millisecond}},
+		{"Time", "2022-03-04T05:06:07Z\n", &[]time.Time{},
+			&[]time.Time{time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)}},
+		{"TextUnmarshaler", "10.0.0.0/8\n::1/128\n", &[]netip.Prefix{},
+			&[]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"),
+				netip.MustParsePrefix("::1/128")}},
+		{"Replace", "3\n", &[]int{1, 2}, &[]int{3}},
+		{"Empty", "# nothing\n", &[]int{1}, &[]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if err := Decode(strings.NewReader(tt.Input), tt.V); err != nil {
+				t.Fatalf("Failed to decode: %+v", err)
+			}
+			if !reflect.DeepEqual(tt.Expected, tt.V) {
+				t.Errorf("Unexpected values.\nexpected: %v\nreceived: %v",
+					tt.Expected, tt.V)
+			}
+		})
+	}
+}
+
+// Tests that Decode returns a DecodeError with the line of the value that could
+// not be converted and does not modify the slice.
+func TestDecode_DecodeError(t *testing.T) {
+	type test struct {
+		Name    string
+		Input   string
+		V       any
+		Line    int
+		Err     error
+		Message string
+	}
+
+	tests := []test{
+		{"Syntax", "1\n\n# c\nabc\n", &[]int{}, 4, strconv.ErrSyntax,
+			`line 4: cannot decode "abc" as int: invalid syntax`},
+		{"Range", "1\n256\n", &[]uint8{}, 2, strconv.ErrRange,
+			`line 2: cannot decode "256" as uint8: value out of range`},
+		{"MultiLine", "\"1\n2\"\n", &[]float64{}, 1, strconv.ErrSyntax,
+			`line 1: cannot decode "1\n2" as float64: invalid syntax`},
+		{"Bool", "yes\n", &[]bool{}, 1, strconv.ErrSyntax,
+			`line 1: cannot decode "yes" as bool: invalid syntax`},
+		{"Duration", "1\n", &[]time.Duration{}, 1, nil,
+			`line 1: cannot decode "1" as time.Duration: ` +
+				`time: missing unit in duration "1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			before := reflect.ValueOf(tt.V).Elem().Len()
+			err := Decode(strings.NewReader(tt.Input), tt.V)
+
+			var de *DecodeError
+			if !errors.As(err, &de) {
+				t.Fatalf("Expected DecodeError, received: %+v", err)
+			}
+			if de.Line != tt.Line {
+				t.Errorf("Unexpected line.\nexpected: %d\nreceived: %d",
+					tt.Line, de.Line)
+			}
+			if tt.Err != nil && !errors.Is(err, tt.Err) {
+				t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+					tt.Err, de.Err)
+			}
+			if err.Error() != tt.Message {
+				t.Errorf("Unexpected message.\nexpected: %q\nreceived: %q",
+					tt.Message, err.Error())
+			}
+			if after := reflect.ValueOf(tt.V).Elem().Len(); after != before {
+				t.Errorf("Slice was modified: length %d, expected %d.",
+					after, before)
+			}
+		})
+	}
+}
+
+// Tests that Reader.Decode includes the file name in a DecodeError and returns
+// parse errors unchanged.
+func TestReader_Decode_Errors(t *testing.T) {
+	r := NewReader(strings.NewReader("1\nx\n"))
+	r.FileName = "ints.lsv"
+	var ints []int
+	err := r.Decode(&ints)
+	expected := `ints.lsv: line 2: cannot decode "x" as int: invalid syntax`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Unexpected error.\nexpected: %s\nreceived: %v",
+			expected, err)
+	}
+
+	err = Decode(strings.NewReader("1\n\"2\n"), &ints)
+	if !errors.Is(err, ErrNoClosingRaw) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrNoClosingRaw, err)
+	}
+}
+
+// Tests that Decode returns ErrDecodeTarget for unsupported targets.
+func TestDecode_ErrDecodeTarget(t *testing.T) {
+	var nilSlice *[]int
+	targets := []any{nil, []int{}, nilSlice, new(int), new([]complex128),
+		new([][]int), new([]*int)}
+
+	for _, v := range targets {
+		err := Decode(strings.NewReader("1\n"), v)
+		if !errors.Is(err, ErrDecodeTarget) {
+			t.Errorf("Unexpected error for %T.\nexpected: %v\nreceived: %v",
+				v, ErrDecodeTarget, err)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NodeKind describes the contents of a Node.
+type NodeKind int
+
+// Node kinds.
+const (
+	// BlankNode is an empty line or a line containing only whitespace.
+	BlankNode NodeKind = iota
+
+	// CommentNode is a line containing only a comment.
+	CommentNode
+
+	// ValueNode is a value with an optional inline comment. A ValueNode spans
+	// several lines if it is a multi-line raw string literal.
+	ValueNode
+
+	// SectionNode is a section header with an optional inline comment. It is
+	// only parsed if Sections is enabled in the Parameters.
+	SectionNode
+)
+
+// String returns the name of the NodeKind.
+func (k NodeKind) String() string {
+	switch k {
+	case BlankNode:
+		return "BlankNode"
+	case CommentNode:
+		return "CommentNode"
+	case ValueNode:
+		return "ValueNode"
+	case SectionNode:
+		return "SectionNode"
+	default:
+		return "NodeKind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// Node is a single line, or multiple lines for a multi-line raw string
+// literal, of a Document.
+//
+// The exported fields can be changed directly. A node that has not been
+// changed since it was parsed is written exactly as it appeared in the source.
+// A changed node is written using the [Writer] encoding rules.
+type Node struct {
+	Kind NodeKind
+
+	// Value is the decoded value of a ValueNode or the name of a SectionNode.
+	Value string
+
+	// Comment is the inline comment of a ValueNode or SectionNode or the text
+	// of a CommentNode, without the Comment character and surrounding
+	// whitespace.
+	Comment string
+
+	// Quoted is true if the value of a ValueNode is written as a raw string
+	// literal.
+	Quoted bool
+
+	// text is the source of the node, including the line ending, and indent is
+	// the leading whitespace on its first line. Both are empty for new nodes.
+	text, indent string
+
+	// parsed is the state of the node when it was parsed.
+	parsed nodeState
+}
+
+// nodeState contains the exported fields of a Node.
+type nodeState struct {
+	kind    NodeKind
+	value   string
+	comment string
+	quoted  bool
+}
+
+// NewValueNode returns a new ValueNode with the value and optional inline
+// comment.
+func NewValueNode(value, comment string) *Node {
+	return &Node{Kind: ValueNode, Value: value, Comment: comment}
+}
+
+// NewCommentNode returns a new CommentNode containing the comment text.
+func NewCommentNode(comment string) *Node {
+	return &Node{Kind: CommentNode, Comment: comment}
+}
+
+// NewSectionNode returns a new SectionNode that starts the section with the
+// name.
+func NewSectionNode(name string) *Node {
+	return &Node{Kind: SectionNode, Value: name}
+}
+
+// NewBlankNode returns a new BlankNode.
+func NewBlankNode() *Node {
+	return &Node{Kind: BlankNode}
+}
+
+// state returns the current state of the node's exported fields.
+func (n *Node) state() nodeState {
+	return nodeState{n.Kind, n.Value, n.Comment, n.Quoted}
+}
+
+// modified returns true if the node is new or has been changed since it was
+// parsed.
+func (n *Node) modified() bool {
+	return n.text == "" || n.state() != n.parsed
+}
+
+// Document is an editable LSV file that preserves the values, comments, blank
+// lines, and quoting of its source. Each line of the source is stored as a Node
+// in Nodes. Writing an unmodified Document reproduces its source exactly.
+type Document struct {
+	Parameters
+
+	// UseCRLF uses \r\n as the line terminator for new and changed nodes if
+	// set to true. It is set when parsing if the first line of the source
+	// ends in \r\n.
+	UseCRLF bool
+
+	// Nodes contains each line of the document in order.
+	Nodes []*Node
+}
+
+// ParseDocument reads all of r and parses it into a Document using the
+// Parameters. It returns the same errors as [Reader.ReadAll].
+func ParseDocument(r io.Reader, p Parameters) (*Document, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Document{Parameters: p}
+	if i := bytes.IndexByte(src, '\n'); i > 0 && src[i-1] == '\r' {
+		d.UseCRLF = true
+	}
+
+	// The Reader reports where each value starts and ends so that the lines in
+	// between, which contain no values, can be parsed as comments and blanks
+	lr := NewCustomReader(bytes.NewReader(src), p)
+	var prev int
+	for {
+		rec, err := lr.ReadRecord()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		start, end := lr.ValueRange()
+		lineStart := int(start.Offset) - (start.Column - 1)
+		lineEnd := len(src)
+		if i := bytes.IndexByte(src[end.Offset:], '\n'); i > -1 {
+			lineEnd = int(end.Offset) + i + 1
+		}
+
+		d.parseLines(string(src[prev:lineStart]))
+
+		n := &Node{
+			Kind:    ValueNode,
+			Value:   rec.Value,
+			Comment: rec.Comment,
+			Quoted:  rec.Quoted,
+			text:    string(src[lineStart:lineEnd]),
+			indent:  string(src[lineStart:start.Offset]),
+		}
+		n.parsed = n.state()
+		d.Nodes = append(d.Nodes, n)
+
+		prev = lineEnd
+	}
+	d.parseLines(string(src[prev:]))
+
+	return d, nil
+}
+
+// parseLines appends a SectionNode, CommentNode, or BlankNode for each line in
+// s, which must not contain any values or invalid section headers.
+func (d *Document) parseLines(s string) {
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if line == "" {
+			continue
+		}
+
+		header := line
+		if d.TrimLeadingSpace {
+			header = strings.TrimLeftFunc(line, unicode.IsSpace)
+		}
+
+		n := &Node{Kind: BlankNode, text: line}
+		if d.isSectionLine(header) {
+			n.Kind = SectionNode
+			n.Value, n.Comment, _, _ = d.parseSection(header)
+			n.indent = line[:len(line)-len(header)]
+		} else if _, comment, found := d.cutComment(line, false); found {
+			n.Kind = CommentNode
+			n.Comment = strings.TrimSpace(comment)
+			n.indent = line[:len(line)-
+				len(strings.TrimLeftFunc(line, unicode.IsSpace))]
+		}
+		n.parsed = n.state()
+		d.Nodes = append(d.Nodes, n)
+	}
+}
+
+// WriteTo writes the document to w. Unmodified nodes are written exactly as
+// they were parsed and new or changed nodes are written using the [Writer]
+// encoding rules, keeping the indentation of changed nodes. It returns the
+// number of bytes written and any error encountered.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if !d.Verify() {
+		return 0, ErrInvalidParams
+	}
+
+	var buff bytes.Buffer
+	enc := NewWriter(&buff)
+	enc.Parameters = d.Parameters
+	enc.UseCRLF = d.UseCRLF
+
+	for i, n := range d.Nodes {
+		var err error
+		if !n.modified() {
+			_, err = enc.w.WriteString(n.text)
+			if err == nil && i < len(d.Nodes)-1 &&
+				!strings.HasSuffix(n.text, "\n") {
+				// The last line of the source may not have a line ending
+				err = enc.writeLineEnd()
+			}
+		} else {
+			err = d.writeNode(enc, n)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := enc.flush(); err != nil {
+		return 0, err
+	}
+
+	return buff.WriteTo(w)
+}
+
+// writeNode writes the new or changed node to the Writer.
+func (d *Document) writeNode(w *Writer, n *Node) error {
+	switch n.Kind {
+	case ValueNode:
+		if _, err := w.w.WriteString(n.indent); err != nil {
+			return err
+		}
+		// An empty value is quoted so that it is not written as a comment
+		return w.writeRecord(
+			Record{ValueComment: ValueComment{n.Value, n.Comment},
+				Quoted: n.Quoted || n.Value == ""})
+	case CommentNode:
+		// Every line of a multi-line comment keeps the indentation
+		for _, line := range strings.Split(n.Comment, "\n") {
+			if _, err := w.w.WriteString(n.indent); err != nil {
+				return err
+			}
+			err := w.writeCommentLine(strings.TrimSuffix(line, "\r"))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case SectionNode:
+		if _, err := w.w.WriteString(n.indent); err != nil {
+			return err
+		}
+		return w.writeSection(n.Value, n.Comment)
+	default:
+		return w.WriteBlankLine()
+	}
+}
+
+// Values returns the values of all ValueNodes in the document in order.
+func (d *Document) Values() []string {
+	var values []string
+	for _, n := range d.Nodes {
+		if n.Kind == ValueNode {
+			values = append(values, n.Value)
+		}
+	}
+	return values
+}
+
+// Find returns the index in Nodes of the first ValueNode with the value or -1
+// if there is none.
+func (d *Document) Find(value string) int {
+	for i, n := range d.Nodes {
+		if n.Kind == ValueNode && n.Value == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// Insert inserts the nodes before the node at index i. If i is len(d.Nodes),
+// the nodes are appended to the end of the document.
+func (d *Document) Insert(i int, nodes ...*Node) {
+	n := len(d.Nodes)
+	d.Nodes = append(d.Nodes, nodes...)
+	copy(d.Nodes[i+len(nodes):], d.Nodes[i:n])
+	copy(d.Nodes[i:], nodes)
+}
+
+// Append adds the nodes to the end of the document.
+func (d *Document) Append(nodes ...*Node) {
+	d.Nodes = append(d.Nodes, nodes...)
+}
+
+// Delete removes the node at index i.
+func (d *Document) Delete(i int) {
+	copy(d.Nodes[i:], d.Nodes[i+1:])
+	d.Nodes[len(d.Nodes)-1] = nil
+	d.Nodes = d.Nodes[:len(d.Nodes)-1]
+}
+
+// Replace changes the value of the ValueNode at index i, keeping its inline
+// comment and indentation.
+func (d *Document) Replace(i int, value string) {
+	d.Nodes[i].Value = value
+}
@@ -0,0 +1,249 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var documentTests = []string{
+	"",
+	"a\nb\nc\n",
+	"a\nb\nc",
+	"a\r\nb\r\n\r\nc\r\n",
+	"# Comment\n\n  a  # Inline\n\n\n\t# Indented\n  \n\"b\"\n",
+	"\"multi\nline\" # Comment\n  \" raw \" \n# Trailing comment",
+	"\"a\"b\" # Comment 1\n\"c\n# Not a comment\n\" #Comment 2\n",
+	"\\# Escaped\n#\n##\nvalue\\#1\n",
+}
+
+// Tests that a Document that is parsed and written without changes reproduces
+// its source exactly.
+func TestDocument_WriteTo_Unmodified(t *testing.T) {
+	for i, src := range documentTests {
+		d, err := ParseDocument(strings.NewReader(src), DefaultParameters())
+		if err != nil {
+			t.Fatalf("Failed to parse document %d: %+v", i, err)
+		}
+
+		var buff bytes.Buffer
+		n, err := d.WriteTo(&buff)
+		if err != nil {
+			t.Fatalf("Failed to write document %d: %+v", i, err)
+		}
+
+		if buff.String() != src {
+			t.Errorf("Document %d changed.\nexpected: %q\nreceived: %q",
+				i, src, buff.String())
+		}
+		if n != int64(len(src)) {
+			t.Errorf("Document %d returned wrong length."+
+				"\nexpected: %d\nreceived: %d", i, len(src), n)
+		}
+	}
+}
+
+// Tests that Document.Values returns the same values as Reader.ReadAll.
+func TestDocument_Values(t *testing.T) {
+	for i, src := range documentTests {
+		d, err := ParseDocument(strings.NewReader(src), DefaultParameters())
+		if err != nil {
+			t.Fatalf("Failed to parse document %d: %+v", i, err)
+		}
+
+		expected, err := NewReader(strings.NewReader(src)).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to read document %d: %+v", i, err)
+		}
+
+		if values := d.Values(); !reflect.DeepEqual(expected, values) {
+			t.Errorf("Unexpected values for document %d."+
+				"\nexpected: %q\nreceived: %q", i, expected, values)
+		}
+	}
+}
+
+// Tests that ParseDocument produces the expected nodes.
+func TestParseDocument(t *testing.T) {
+	src := "# Comment\n\n  a  # Inline\n\"b\nc\"\n\t#\n  \n"
+	expected := []nodeState{
+		{CommentNode, "", "Comment", false},
+		{BlankNode, "", "", false},
+		{ValueNode, "a", "Inline", false},
+		{ValueNode, "b\nc", "", true},
+		{CommentNode, "", "", false},
+		{BlankNode, "", "", false},
+	}
+
+	d, err := ParseDocument(strings.NewReader(src), DefaultParameters())
+	if err != nil {
+		t.Fatalf("Failed to parse document: %+v", err)
+	}
+
+	var nodes []nodeState
+	for _, n := range d.Nodes {
+		nodes = append(nodes, n.state())
+	}
+
+	if !reflect.DeepEqual(expected, nodes) {
+		t.Errorf("Unexpected nodes.\nexpected: %+v\nreceived: %+v",
+			expected, nodes)
+	}
+	if d.UseCRLF {
+		t.Errorf("UseCRLF set for document with LF line endings.")
+	}
+}
+
+// Tests that ParseDocument returns a ParseError for an invalid source.
+func TestParseDocument_ParseError(t *testing.T) {
+	_, err := ParseDocument(strings.NewReader("a\n\"b\n"), DefaultParameters())
+	if !errors.Is(err, ErrNoClosingRaw) {
+		t.Errorf("Unexpected error: %+v", err)
+	}
+}
+
+// Tests that Document edits are written with the expected output while leaving
+// the rest of the document untouched.
+func TestDocument_Edit(t *testing.T) {
+	type test struct {
+		Name   string
+		Input  string
+		Edit   func(d *Document)
+		Output string
+	}
+
+	tests := []test{{
+		Name:  "Append",
+		Input: "a  # Comment\nb",
+		Edit: func(d *Document) {
+			d.Append(NewValueNode("c", ""), NewValueNode(" d", "New"))
+		},
+		Output: "a  # Comment\nb\nc\n\" d\"\t# New\n",
+	}, {
+		Name:  "Insert",
+		Input: "# Header\n\na\nb\n",
+		Edit: func(d *Document) {
+			d.Insert(d.Find("b"), NewCommentNode("New"), NewValueNode("c", ""))
+			d.Insert(0, NewBlankNode())
+		},
+		Output: "\n# Header\n\na\n# New\nc\nb\n",
+	}, {
+		Name:  "Delete",
+		Input: "a\n# Comment\n\"b\nc\"\nd\n",
+		Edit: func(d *Document) {
+			d.Delete(d.Find("b\nc"))
+		},
+		Output: "a\n# Comment\nd\n",
+	}, {
+		Name:  "Replace",
+		Input: "a\n  b    # Keep this\nc\n",
+		Edit: func(d *Document) {
+			d.Replace(d.Find("b"), "b#2")
+		},
+		Output: "a\n  b\\#2\t# Keep this\nc\n",
+	}, {
+		Name:  "ChangeComment",
+		Input: "  # Old\na\n",
+		Edit: func(d *Document) {
+			d.Nodes[0].Comment = "New"
+			d.Nodes[1].Quoted = true
+		},
+		Output: "  # New\n\"a\"\n",
+	}, {
+		Name:  "MultiLineComment",
+		Input: "a\n  # Old\n  b\n",
+		Edit: func(d *Document) {
+			d.Nodes[1].Comment = "New\nComment"
+		},
+		Output: "a\n  # New\n  # Comment\n  b\n",
+	}, {
+		Name:  "CRLF",
+		Input: "a\r\nb\r\n",
+		Edit: func(d *Document) {
+			d.Replace(0, "c")
+			d.Append(NewBlankNode(), NewValueNode("d", ""))
+		},
+		Output: "c\r\nb\r\n\r\nd\r\n",
+	},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			d, err := ParseDocument(
+				strings.NewReader(tt.Input), DefaultParameters())
+			if err != nil {
+				t.Fatalf("Failed to parse document: %+v", err)
+			}
+
+			tt.Edit(d)
+
+			var buff bytes.Buffer
+			if _, err = d.WriteTo(&buff); err != nil {
+				t.Fatalf("Failed to write document: %+v", err)
+			}
+
+			if buff.String() != tt.Output {
+				t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+					tt.Output, buff.String())
+			}
+		})
+	}
+}
+
+// Tests that an empty value with a comment added or replaced in a Document is
+// read back as an empty value after the Document is written.
+func TestDocument_WriteTo_EmptyValue(t *testing.T) {
+	d, err := ParseDocument(
+		strings.NewReader("a # keep\nb\n"), DefaultParameters())
+	if err != nil {
+		t.Fatalf("Failed to parse document: %+v", err)
+	}
+	d.Replace(d.Find("a"), "")
+	d.Append(NewValueNode("", "note"))
+
+	var buff bytes.Buffer
+	if _, err = d.WriteTo(&buff); err != nil {
+		t.Fatalf("Failed to write document: %+v", err)
+	}
+	expected := "\"\"\t# keep\nb\n\"\"\t# note\n"
+	if buff.String() != expected {
+		t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+			expected, buff.String())
+	}
+
+	d, err = ParseDocument(&buff, DefaultParameters())
+	if err != nil {
+		t.Fatalf("Failed to parse written document: %+v", err)
+	}
+	if values := d.Values(); !reflect.DeepEqual(
+		[]string{"", "b", ""}, values) {
+		t.Errorf("Unexpected values.\nexpected: %q\nreceived: %q",
+			[]string{"", "b", ""}, values)
+	}
+}
+
+// Tests that Document.Find returns -1 when the value does not exist and does
+// not match comments.
+func TestDocument_Find(t *testing.T) {
+	d, err := ParseDocument(
+		strings.NewReader("# a\na # b\nb\n"), DefaultParameters())
+	if err != nil {
+		t.Fatalf("Failed to parse document: %+v", err)
+	}
+
+	for value, expected := range map[string]int{"a": 1, "b": 2, "c": -1} {
+		if i := d.Find(value); i != expected {
+			t.Errorf("Unexpected index for %q.\nexpected: %d\nreceived: %d",
+				value, expected, i)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// ErrEncodeSource is returned by Encode when v is not a slice, or a pointer to
+// a slice, of a supported type.
+var ErrEncodeSource = errors.New("encode source must be a slice of a " +
+	"supported type")
+
+var (
+	marshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType  = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// Encode writes every element of the slice v to w using the default Parameters.
+// See [Writer.Encode] for the supported types.
+func Encode(w io.Writer, v any) error {
+	return NewWriter(w).Encode(v)
+}
+
+// Encode writes every element of the slice v, which can also be a pointer to a
+// slice, and then calls [Writer.Flush]. The elements can be strings, bools,
+// integers, floats, or of any type that implements [encoding.TextMarshaler] or
+// [fmt.Stringer], such as [net.IP] or [time.Duration]. TextMarshaler is used
+// over Stringer if a type implements both.
+//
+// Every element is converted before anything is written, so nothing is written
+// if an element cannot be converted. Each value is quoted and escaped as needed
+// and, like [Writer.Write], Encode returns ErrUnrepresentable if a value cannot
+// be written in a way that is read back unchanged. If v is not a supported
+// type, it returns ErrEncodeSource.
+func (w *Writer) Encode(v any) error {
+	return w.EncodeComments(v, nil)
+}
+
+// EncodeComments writes every element of the slice v like [Writer.Encode] with
+// the inline comment returned by comment for the index of each element. No
+// comment is written if comment is nil or returns an empty string.
+func (w *Writer) EncodeComments(v any, comment func(i int) string) error {
+	if !w.Verify() {
+		return ErrInvalidParams
+	}
+
+	values, err := encodeSlice(v)
+	if err != nil {
+		return err
+	}
+
+	for i, value := range values {
+		var c string
+		if comment != nil {
+			c = comment(i)
+		}
+
+		// An empty value can only be written as a raw string literal, which
+		// also keeps its comment inline
+		err = w.writeValue(value, c, value == "")
+		if err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	return w.flush()
+}
+
+// encodeSlice converts every element of the slice, or pointer to a slice, v to
+// its text form.
+func encodeSlice(v any) ([]string, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice {
+		return nil, ErrEncodeSource
+	}
+
+	format := formatter(rv.Type().Elem())
+	if format == nil {
+		return nil, ErrEncodeSource
+	}
+
+	values := make([]string, rv.Len())
+	for i := range values {
+		var err error
+		values[i], err = format(rv.Index(i))
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	return values, nil
+}
+
+// formatter returns a function that converts a value of the type t to its text
+// form. It returns nil if the type is not supported.
+func formatter(t reflect.Type) func(e reflect.Value) (string, error) {
+	// Slice elements are addressable, so methods with a pointer receiver can
+	// also be used
+	ptr := reflect.PointerTo(t)
+	if t.Implements(marshalerType) || ptr.Implements(marshalerType) {
+		return func(e reflect.Value) (string, error) {
+			if !t.Implements(marshalerType) {
+				e = e.Addr()
+			}
+			text, err := e.Interface().(encoding.TextMarshaler).MarshalText()
+			return string(text), err
+		}
+	} else if t.Implements(stringerType) || ptr.Implements(stringerType) {
+		return func(e reflect.Value) (string, error) {
+			if !t.Implements(stringerType) {
+				e = e.Addr()
+			}
+			return e.Interface().(fmt.Stringer).String(), nil
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return func(e reflect.Value) (string, error) {
+			return e.String(), nil
+		}
+	case reflect.Bool:
+		return func(e reflect.Value) (string, error) {
+			return strconv.FormatBool(e.Bool()), nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return func(e reflect.Value) (string, error) {
+			return strconv.FormatInt(e.Int(), 10), nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return func(e reflect.Value) (string, error) {
+			return strconv.FormatUint(e.Uint(), 10), nil
+		}
+	case reflect.Float32, reflect.Float64:
+		return func(e reflect.Value) (string, error) {
+			return strconv.FormatFloat(e.Float(), 'g', -1, t.Bits()), nil
+		}
+	}
+	return nil
+}
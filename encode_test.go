@@ -0,0 +1,165 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// point implements fmt.Stringer with a pointer receiver.
+type point struct{ X, Y int }
+
+func (p *point) String() string {
+	return strconv.Itoa(p.X) + "," + strconv.Itoa(p.Y)
+}
+
+// badMarshaler implements encoding.TextMarshaler and always fails.
+type badMarshaler struct{}
+
+var errBadMarshaler = errors.New("bad marshaler")
+
+func (badMarshaler) MarshalText() ([]byte, error) {
+	return nil, errBadMarshaler
+}
+
+// Tests that Encode writes each supported element type as expected.
+func TestEncode(t *testing.T) {
+	type test struct {
+		Name     string
+		V        any
+		Expected string
+	}
+
+	type name string
+
+	tests := []test{
+		{"String", []string{"a", " b", ""}, "a\n\" b\"\n\"\"\n"},
+		{"NamedString", []name{"a#b"}, "a\\#b\n"},
+		{"Pointer", &[]string{"a"}, "a\n"},
+		{"Int", []int{1, -2}, "1\n-2\n"},
+		{"Uint8", []uint8{0, 255}, "0\n255\n"},
+		{"Float64", []float64{1.5, -2e30}, "1.5\n-2e+30\n"},
+		{"Float32", []float32{0.1}, "0.1\n"},
+		{"Bool", []bool{true, false}, "true\nfalse\n"},
+		{"Duration", []time.Duration{90 * time.Minute, 0},
+			"1h30m0s\n0s\n"},
+		{"Time", []time.Time{time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)},
+			"2022-03-04T05:06:07Z\n"},
+		{"IP", []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("::1")},
+			"10.0.0.1\n::1\n"},
+		{"PointerReceiver", []point{{1, 2}}, "1,2\n"},
+		{"Empty", []int{}, ""},
+		{"Nil", []int(nil), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var buff bytes.Buffer
+			if err := Encode(&buff, tt.V); err != nil {
+				t.Fatalf("Failed to encode: %+v", err)
+			}
+			if buff.String() != tt.Expected {
+				t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+					tt.Expected, buff.String())
+			}
+		})
+	}
+}
+
+// Tests that values written by Encode are read back by Decode.
+func TestEncode_RoundTrip(t *testing.T) {
+	values := []any{
+		&[]string{"a", "", "  b # c", "\"d\"\ne"},
+		&[]int64{0, -1, 1 << 62},
+		&[]float64{0.1, 1e300, -3},
+		&[]time.Duration{time.Nanosecond, 25 * time.Hour},
+		&[]time.Time{time.Date(2022, 3, 4, 5, 6, 7, 8, time.UTC)},
+	}
+
+	for _, v := range values {
+		var buff bytes.Buffer
+		if err := Encode(&buff, v); err != nil {
+			t.Fatalf("Failed to encode %T: %+v", v, err)
+		}
+
+		decoded := reflect.New(reflect.TypeOf(v).Elem())
+		if err := Decode(&buff, decoded.Interface()); err != nil {
+			t.Fatalf("Failed to decode %T: %+v", v, err)
+		}
+		if !reflect.DeepEqual(v, decoded.Interface()) {
+			t.Errorf("Unexpected values.\nexpected: %v\nreceived: %v",
+				v, decoded.Interface())
+		}
+	}
+}
+
+// Tests that Writer.EncodeComments writes the comment for each element.
+func TestWriter_EncodeComments(t *testing.T) {
+	ports := []uint16{22, 80, 443}
+	names := []string{"ssh", "", "https"}
+
+	var buff bytes.Buffer
+	w := NewWriter(&buff)
+	w.AlignComments = true
+	err := w.EncodeComments(ports, func(i int) string { return names[i] })
+	if err != nil {
+		t.Fatalf("Failed to encode: %+v", err)
+	}
+
+	expected := "22\t# ssh\n80\n443\t# https\n"
+	if buff.String() != expected {
+		t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+			expected, buff.String())
+	}
+
+	buff.Reset()
+	err = NewWriter(&buff).EncodeComments(
+		[]string{""}, func(int) string { return "empty" })
+	if err != nil {
+		t.Fatalf("Failed to encode: %+v", err)
+	}
+	if expected = "\"\"\t# empty\n"; buff.String() != expected {
+		t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+			expected, buff.String())
+	}
+}
+
+// Tests that Encode returns the expected errors and writes nothing if an
+// element cannot be converted.
+func TestEncode_Error(t *testing.T) {
+	var nilSlice *[]int
+	for _, v := range []any{nil, 5, nilSlice, []complex64{1}, [][]int{{1}},
+		map[string]int{}} {
+		if err := Encode(&bytes.Buffer{}, v); !errors.Is(err, ErrEncodeSource) {
+			t.Errorf("Unexpected error for %T.\nexpected: %v\nreceived: %v",
+				v, ErrEncodeSource, err)
+		}
+	}
+
+	var buff bytes.Buffer
+	err := Encode(&buff, []badMarshaler{{}, {}})
+	if !errors.Is(err, errBadMarshaler) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			errBadMarshaler, err)
+	} else if buff.Len() != 0 {
+		t.Errorf("Unexpected output: %q", buff.String())
+	}
+
+	err = NewWriter(&buff).EncodeComments(
+		[]int{1}, func(int) string { return "a\nb" })
+	if !errors.Is(err, ErrCommentNewline) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrCommentNewline, err)
+	}
+}
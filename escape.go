@@ -0,0 +1,150 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// sequenceChars are the characters that can follow the Escape character to
+// start an escape sequence when EscapeSequences is set.
+const sequenceChars = "ntr0xuU"
+
+// decodeSequence decodes the escape sequence at the start of s, which directly
+// follows an Escape character. It returns the decoded text and the length of
+// the sequence in s. If s does not start with a valid escape sequence, ok is
+// false. The Escape and Raw characters escape themselves.
+func (p Parameters) decodeSequence(s string) (decoded string, n int, ok bool) {
+	c, size := utf8.DecodeRuneInString(s)
+	switch c {
+	case p.Escape, p.Raw:
+		return s[:size], size, true
+	case 'n':
+		return "\n", size, true
+	case 't':
+		return "\t", size, true
+	case 'r':
+		return "\r", size, true
+	case '0':
+		return "\x00", size, true
+	case 'x':
+		if v, ok := parseHex(s[size:], 2); ok {
+			return string([]byte{byte(v)}), size + 2, true
+		}
+	case 'u', 'U':
+		digits := 4
+		if c == 'U' {
+			digits = 8
+		}
+		if v, ok := parseHex(s[size:], digits); ok && utf8.ValidRune(rune(v)) {
+			return string(rune(v)), size + digits, true
+		}
+	}
+	return "", 0, false
+}
+
+// parseHex parses the first n characters of s as a hexadecimal number.
+func parseHex(s string, n int) (uint32, bool) {
+	if len(s) < n {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(s[:n], 16, 32)
+	return uint32(v), err == nil
+}
+
+// decodeSequences replaces every valid escape sequence in the raw string
+// literal with the text it represents. An Escape character that does not start
+// a valid escape sequence is kept.
+func (p Parameters) decodeSequences(s string) string {
+	if !strings.ContainsRune(s, p.Escape) {
+		return s
+	}
+	return string(p.appendSequences(nil, s))
+}
+
+// appendSequences appends s to dst with every valid escape sequence decoded, as
+// in decodeSequences, and returns the extended slice.
+func (p Parameters) appendSequences(dst []byte, s string) []byte {
+	esc := string(p.Escape)
+	for {
+		i := strings.Index(s, esc)
+		if i == -1 {
+			break
+		}
+		dst = append(dst, s[:i]...)
+		s = s[i+len(esc):]
+
+		decoded, n, ok := p.decodeSequence(s)
+		if !ok {
+			dst = append(dst, esc...)
+			continue
+		}
+		dst = append(dst, decoded...)
+		s = s[n:]
+	}
+	return append(dst, s...)
+}
+
+// needsSequence determines if the value contains a character that can only be
+// written as an escape sequence: a control character other than a newline or
+// a byte that is not valid UTF-8.
+func needsSequence(value string) bool {
+	if !utf8.ValidString(value) {
+		return true
+	}
+	for _, c := range value {
+		if c != '\n' && unicode.IsControl(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeSequences escapes the value so that it can be placed between the
+// opening and closing Raw characters of a raw string literal when
+// EscapeSequences is set. Every Escape and Raw character is escaped and every
+// control character other than a newline is written as an escape sequence, as
+// is every byte that is not valid UTF-8. Newlines are kept so that the literal
+// spans multiple lines.
+func (p Parameters) encodeSequences(value string) string {
+	esc := string(p.Escape)
+	var b strings.Builder
+	for i := 0; i < len(value); {
+		c, size := utf8.DecodeRuneInString(value[i:])
+		switch {
+		case c == utf8.RuneError && size == 1:
+			b.WriteString(esc + "x" + hexDigits(uint32(value[i]), 2))
+		case c == p.Escape || c == p.Raw:
+			b.WriteString(esc + value[i:i+size])
+		case c == '\t':
+			b.WriteString(esc + "t")
+		case c == '\r':
+			b.WriteString(esc + "r")
+		case c == 0:
+			b.WriteString(esc + "0")
+		case c != '\n' && unicode.IsControl(c) && c < utf8.RuneSelf:
+			b.WriteString(esc + "x" + hexDigits(uint32(c), 2))
+		case c != '\n' && unicode.IsControl(c):
+			b.WriteString(esc + "u" + hexDigits(uint32(c), 4))
+		default:
+			b.WriteString(value[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// hexDigits returns v as an uppercase hexadecimal number padded with zeros to n
+// digits.
+func hexDigits(v uint32, n int) string {
+	s := strings.ToUpper(strconv.FormatUint(uint64(v), 16))
+	return strings.Repeat("0", n-len(s)) + s
+}
@@ -0,0 +1,277 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sequenceParameters returns the default Parameters with EscapeSequences set.
+func sequenceParameters(strict bool) Parameters {
+	p := DefaultParameters()
+	p.EscapeSequences = true
+	p.Strict = strict
+	return p
+}
+
+// Tests that Parameters.decodeSequence decodes every valid escape sequence and
+// rejects invalid ones.
+func TestParameters_decodeSequence(t *testing.T) {
+	type test struct {
+		Input   string
+		Decoded string
+		N       int
+		OK      bool
+	}
+
+	tests := []test{
+		{`\`, `\`, 1, true},
+		{`"a`, `"`, 1, true},
+		{"n", "\n", 1, true},
+		{"tn", "\t", 1, true},
+		{"r", "\r", 1, true},
+		{"0", "\x00", 1, true},
+		{"x7F", "\x7f", 3, true},
+		{"xffa", "\xff", 3, true},
+		{"u00e9", "é", 5, true},
+		{"U0001F600", "😀", 9, true},
+		{"#", "", 0, false},
+		{"a", "", 0, false},
+		{"", "", 0, false},
+		{"x7", "", 0, false},
+		{"xG0", "", 0, false},
+		{"x+1", "", 0, false},
+		{"u12", "", 0, false},
+		{"uD800", "", 0, false},
+		{"U00110000", "", 0, false},
+	}
+
+	p := DefaultParameters()
+	for _, tt := range tests {
+		decoded, n, ok := p.decodeSequence(tt.Input)
+		if decoded != tt.Decoded || n != tt.N || ok != tt.OK {
+			t.Errorf("Unexpected result for %q.\nexpected: %q, %d, %t"+
+				"\nreceived: %q, %d, %t",
+				tt.Input, tt.Decoded, tt.N, tt.OK, decoded, n, ok)
+		}
+	}
+}
+
+// Tests that Parameters.decodeSequences decodes every valid escape sequence and
+// keeps the Escape character of invalid ones.
+func TestParameters_decodeSequences(t *testing.T) {
+	tests := map[string]string{
+		"":                 "",
+		"abc":              "abc",
+		`a\tb\nc`:          "a\tb\nc",
+		`\\n`:              `\n`,
+		`\\\n`:             "\\\n",
+		`\"a\"`:            `"a"`,
+		`\q\x1\`:           `\q\x1\`,
+		`\x00\u0000\0`:     "\x00\x00\x00",
+		`a\U0010FFFFb`:     "a\U0010FFFFb",
+		"\\u00e9\\u00E9\n": "éé\n",
+	}
+
+	p := DefaultParameters()
+	for input, expected := range tests {
+		if decoded := p.decodeSequences(input); decoded != expected {
+			t.Errorf("Unexpected result for %q.\nexpected: %q\nreceived: %q",
+				input, expected, decoded)
+		}
+	}
+}
+
+// Tests that Parameters.encodeSequences escapes every character that needs it.
+func TestParameters_encodeSequences(t *testing.T) {
+	tests := map[string]string{
+		"":              "",
+		"abc # d":       "abc # d",
+		"a\tb\r\nc\x00": `a\tb\r` + "\n" + `c\0`,
+		`a"b\c`:         `a\"b\\c`,
+		"\x1b\x7f":      `\x1B\x7F`,
+		"\u0085é":       `\u0085é`,
+		"a\xffb":        `a\xFFb`,
+	}
+
+	p := DefaultParameters()
+	for input, expected := range tests {
+		if encoded := p.encodeSequences(input); encoded != expected {
+			t.Errorf("Unexpected result for %q.\nexpected: %q\nreceived: %q",
+				input, expected, encoded)
+		}
+	}
+}
+
+// Tests that needsSequence returns true only for values with control
+// characters other than newlines or invalid UTF-8.
+func Test_needsSequence(t *testing.T) {
+	tests := map[string]bool{
+		"":         false,
+		"a b\nc":   false,
+		"é😀":       false,
+		"\uFFFD":   false,
+		"a\tb":     true,
+		"a\r\n":    true,
+		"\x00":     true,
+		"\u0085":   true,
+		"a\xffb":   true,
+		"\xe2\x82": true,
+	}
+
+	for value, expected := range tests {
+		if needsSequence(value) != expected {
+			t.Errorf("Unexpected result for %q.\nexpected: %t\nreceived: %t",
+				value, expected, !expected)
+		}
+	}
+}
+
+var sequenceTests = []struct {
+	Name   string
+	Input  string
+	Output []string
+	Strict error
+}{
+	{"Unquoted", "a\\tb\n", []string{"a\\tb"}, ErrInvalidEscape},
+	{"Sequences", "\"a\\tb\\r\\n\\0\"\n", []string{"a\tb\r\n\x00"}, nil},
+	{"Hex", "\"\\x41\\xff\\u00e9\\U0001F600\"\n",
+		[]string{"A\xffé😀"}, nil},
+	{"EscapedEscape", "\"\\\\n\\\\\" # c\n", []string{"\\n\\"}, nil},
+	{"EscapedRaw", "\"a\\\" # b\\\"\"\n", []string{"a\" # b\""}, nil},
+	{"EscapedRawLineEnd", "\"a\\\"\nb\"\n", []string{"a\"\nb"}, nil},
+	{"OddEscapesLineEnd", "\"a\\\\\\\"\nb\"\n", []string{"a\\\"\nb"}, nil},
+	{"InvalidSequence", "\"a\\qb\"\n", []string{"a\\qb"}, ErrInvalidEscape},
+	{"InvalidHex", "\"\\x4\"\n", []string{"\\x4"}, ErrInvalidEscape},
+	{"Surrogate", "\"\\uD800\"\n", []string{"\\uD800"}, ErrInvalidEscape},
+}
+
+// Tests that Reader.ReadAll and SplitParams decode escape sequences in raw
+// string literals in both the lenient and strict grammar.
+func TestReader_ReadAll_EscapeSequences(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		p := sequenceParameters(strict)
+		for _, tt := range sequenceTests {
+			t.Run(tt.Name, func(t *testing.T) {
+				values, err := NewCustomReader(
+					strings.NewReader(tt.Input), p).ReadAll()
+				checkSequenceResult(t, tt.Output, tt.Strict, strict,
+					values, err)
+
+				values, err = SplitParams(tt.Input, p)
+				checkSequenceResult(t, tt.Output, tt.Strict, strict,
+					values, err)
+			})
+		}
+	}
+}
+
+// checkSequenceResult checks that the values match the expected ones or, in
+// strict mode, that the error is the expected one.
+func checkSequenceResult(t *testing.T, expected []string, strictErr error,
+	strict bool, values []string, err error) {
+	if strict && strictErr != nil {
+		if !errors.Is(err, strictErr) {
+			t.Errorf("Unexpected error in strict mode."+
+				"\nexpected: %v\nreceived: %v", strictErr, err)
+		}
+	} else if err != nil {
+		t.Errorf("Unexpected error (strict %t): %+v", strict, err)
+	} else if !reflect.DeepEqual(expected, values) {
+		t.Errorf("Unexpected values (strict %t).\nexpected: %q\nreceived: %q",
+			strict, expected, values)
+	}
+}
+
+// Tests that every combination of special characters written by a Writer with
+// EscapeSequences set is read back unchanged by Reader and SplitParams, in both
+// the lenient and strict grammar.
+func TestWriter_EscapeSequences_RoundTrip(t *testing.T) {
+	params := []Parameters{sequenceParameters(false), sequenceParameters(true),
+		{Comment: ';', Raw: '\'', Escape: '/', TrimLeadingSpace: true,
+			EscapeSequences: true}}
+	for _, p := range params {
+		alphabet := []string{"a", "n", " ", "\n", "\r", "\t", "\x00", "\xff",
+			string(p.Comment), string(p.Raw), string(p.Escape)}
+		values := []string{""}
+		for n := 1; n <= 4; n++ {
+			values = append(values, combinations(alphabet, n)...)
+		}
+
+		var buff bytes.Buffer
+		w := NewCustomWriter(&buff, p)
+		for _, value := range values {
+			rec := Record{ValueComment: ValueComment{value, "c"}}
+			rec.Quoted = value == ""
+			if err := w.WriteRecord(rec); err != nil {
+				t.Fatalf("Failed to write %q: %+v", value, err)
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			t.Fatalf("Failed to flush: %+v", err)
+		}
+
+		read, err := NewCustomReader(
+			bytes.NewReader(buff.Bytes()), p).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to read: %+v", err)
+		}
+		if !reflect.DeepEqual(values, read) {
+			t.Errorf("Reader did not read back values for %+v.", p)
+		}
+
+		split, err := SplitParams(buff.String(), p)
+		if err != nil {
+			t.Fatalf("Failed to split: %+v", err)
+		}
+		if !reflect.DeepEqual(values, split) {
+			t.Errorf("SplitParams did not read back values for %+v.", p)
+		}
+	}
+}
+
+// Tests that the Writer with EscapeSequences set writes control characters as
+// escape sequences.
+func TestWriter_Write_EscapeSequences(t *testing.T) {
+	type test struct {
+		Value    string
+		Expected string
+	}
+
+	tests := []test{
+		{"a", "a\n"},
+		{"a # b", "a \\# b\n"},
+		{"a\tb", "\"a\\tb\"\n"},
+		{"\"a\\", "\"\\\"a\\\\\"\n"},
+		{"a\r\nb", "\"a\\r\nb\"\n"},
+		{"\x1b[0m", "\"\\x1B[0m\"\n"},
+		{"\xff", "\"\\xFF\"\n"},
+	}
+
+	for _, strict := range []bool{false, true} {
+		for _, tt := range tests {
+			var buff bytes.Buffer
+			w := NewCustomWriter(&buff, sequenceParameters(strict))
+			if err := w.Write(tt.Value); err != nil {
+				t.Errorf("Failed to write %q: %+v", tt.Value, err)
+			}
+			w.Flush()
+
+			if buff.String() != tt.Expected {
+				t.Errorf("Unexpected output for %q (strict %t)."+
+					"\nexpected: %q\nreceived: %q",
+					tt.Value, strict, tt.Expected, buff.String())
+			}
+		}
+	}
+}
@@ -8,11 +8,15 @@
 package lsv
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"testing/fstest"
+	"time"
 )
 
 // This example shows how the [lsv.Reader] can read in a list with comments and
@@ -145,3 +149,331 @@ func ExampleWriter_WriteAllWithComments() {
 	// "  green"	# Another comment
 	// "  red"	# A third comment
 }
+
+// This example shows how [Reader.ReadRecord] can read in each value along with
+// its comments.
+func ExampleReader_ReadRecord() {
+	in := `# Dairy
+eggs # large
+"  milk"
+`
+	r := NewReader(strings.NewReader(in))
+
+	for {
+		rec, err := r.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("%q %q %t %q\n",
+			rec.Value, rec.Comment, rec.Quoted, rec.BlockComment)
+	}
+	// Output:
+	// "eggs" "large" false ["Dairy"]
+	// "  milk" "" true []
+}
+
+// This example shows how [Reader.ReadBytes] with ReuseValue set can read a
+// large list without allocating for each value.
+func ExampleReader_ReadBytes() {
+	in := `ads.example.com
+tracker.example.com # Analytics
+`
+	r := NewReader(strings.NewReader(in))
+	r.ReuseValue = true
+
+	var total int
+	for {
+		value, err := r.ReadBytes()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		total += len(value)
+	}
+	fmt.Println(total)
+	// Output:
+	// 34
+}
+
+// This example shows how [ScanValues] splits an LSV into values for a
+// [bufio.Scanner].
+func ExampleScanValues() {
+	in := `# Hosts
+example.com # Primary
+"multi
+line"
+`
+	s := bufio.NewScanner(strings.NewReader(in))
+	s.Split(ScanValues)
+
+	for s.Scan() {
+		fmt.Printf("%q\n", s.Text())
+	}
+	if err := s.Err(); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// "example.com"
+	// "multi\nline"
+}
+
+// This example shows how a [Document] can be used to edit an LSV file without
+// losing its comments and formatting.
+func ExampleDocument() {
+	in := `# Fruit
+bananas
+apples  # green
+
+# Dairy
+eggs    # large
+`
+	d, err := ParseDocument(strings.NewReader(in), DefaultParameters())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	d.Replace(d.Find("apples"), "pears")
+	d.Delete(d.Find("bananas"))
+	d.Append(NewValueNode("milk", "skimmed"))
+
+	if _, err = d.WriteTo(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// # Fruit
+	// pears	# green
+	//
+	// # Dairy
+	// eggs    # large
+	// milk	# skimmed
+}
+
+// This example shows how the strict escape grammar decodes escape sequences in
+// both unquoted and quoted values.
+func ExampleParameters_strict() {
+	in := `a\\b  \# Not a comment
+"  \"quoted\" # Not a comment" # A comment
+`
+	p := DefaultParameters()
+	p.Strict = true
+	r := NewCustomReader(strings.NewReader(in), p)
+
+	values, err := r.ReadAll()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, value := range values {
+		fmt.Printf("%q\n", value)
+	}
+	// Output:
+	// "a\\b  # Not a comment"
+	// "  \"quoted\" # Not a comment"
+}
+
+// This example shows how to write and read control characters as escape
+// sequences.
+func ExampleParameters_escapeSequences() {
+	p := DefaultParameters()
+	p.EscapeSequences = true
+
+	var b strings.Builder
+	w := NewCustomWriter(&b, p)
+	err := w.WriteAll([]string{"Name\tAge", "\x1b[1mbold\x1b[0m"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(b.String())
+
+	values, err := NewCustomReader(strings.NewReader(b.String()), p).ReadAll()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%q\n", values)
+	// Output:
+	// "Name\tAge"
+	// "\x1B[1mbold\x1B[0m"
+	// ["Name\tAge" "\x1b[1mbold\x1b[0m"]
+}
+
+// This example shows how to decode values into a typed slice.
+func ExampleDecode() {
+	in := `# Timeouts
+1s
+1m30s # Retry
+`
+	var timeouts []time.Duration
+	if err := Decode(strings.NewReader(in), &timeouts); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(timeouts)
+
+	var ports []uint16
+	err := Decode(strings.NewReader("80\n443\nhttp\n"), &ports)
+	fmt.Println(err)
+	// Output:
+	// [1s 1m30s]
+	// line 3: cannot decode "http" as uint16: invalid syntax
+}
+
+// This example shows how to encode a typed slice with a comment for each
+// element.
+func ExampleWriter_EncodeComments() {
+	timeouts := []time.Duration{time.Second, 90 * time.Second}
+	names := []string{"connect", "retry"}
+
+	w := NewWriter(os.Stdout)
+	w.LeadingCommentSpace = " "
+	err := w.EncodeComments(timeouts, func(i int) string { return names[i] })
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// 1s # connect
+	// 1m30s # retry
+}
+
+// This example shows how to read a list of typed values with a custom parser.
+func ExampleList() {
+	l := List[int]{
+		Parse: func(s string) (int, error) {
+			i, err := strconv.ParseInt(s, 0, 0)
+			return int(i), err
+		},
+	}
+	if err := l.UnmarshalText([]byte("0x10 # hex\n0o17\n42\n")); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(l.Values)
+	// Output: [16 15 42]
+}
+
+// This example shows how to read key/value pairs.
+func ExampleKVReader() {
+	in := `# Server
+host = example.com # The host name
+"first name" = "  Jo "
+url = https://example.com/\#top
+`
+	r := NewKVReader(strings.NewReader(in))
+
+	pairs, err := r.ReadAll()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, kv := range pairs {
+		fmt.Printf("%q: %q\n", kv.Key, kv.Value)
+	}
+	// Output:
+	// "host": "example.com"
+	// "first name": "  Jo "
+	// "url": "https://example.com/#top"
+}
+
+// This example shows how to write key/value pairs with aligned comments.
+func ExampleKVWriter() {
+	w := NewKVWriter(os.Stdout)
+	w.AlignComments = true
+	w.LeadingCommentSpace = " "
+
+	err := w.WriteComment("host", "example.com", "The host name")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = w.WriteComment("port", "8080", "The port"); err != nil {
+		log.Fatal(err)
+	}
+	if err = w.Write("first name", "  Jo "); err != nil {
+		log.Fatal(err)
+	}
+
+	w.Flush()
+	if err = w.Error(); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// host = example.com # The host name
+	// port = 8080        # The port
+	// first name = "  Jo "
+}
+
+// This example shows how to read values grouped by section.
+func ExampleReader_ReadSections() {
+	in := `localhost
+[production]
+example.com # Primary
+\[::1]
+[staging]
+`
+	p := DefaultParameters()
+	p.Sections = true
+	r := NewCustomReader(strings.NewReader(in), p)
+
+	sections, order, err := r.ReadSections()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, name := range order {
+		fmt.Printf("%q: %q\n", name, sections[name])
+	}
+	// Output:
+	// "": ["localhost"]
+	// "production": ["example.com" "[::1]"]
+	// "staging": []
+}
+
+// This example shows how to read a file that includes other files and where
+// each value came from.
+func ExampleIncludeReader() {
+	fsys := fstest.MapFS{
+		"hosts.lsv": {
+			Data: []byte("example.com\n#include lists/ads.lsv\n")},
+		"lists/ads.lsv":  {Data: []byte("ads.example\n#include more.lsv\n")},
+		"lists/more.lsv": {Data: []byte("tracker.example # Added later\n")},
+	}
+	r := NewIncludeReader(fsys, "hosts.lsv")
+
+	for {
+		rec, err := r.ReadRecord()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s:%d: %s\n", rec.File, rec.Line, rec.Value)
+	}
+	// Output:
+	// hosts.lsv:1: example.com
+	// lists/ads.lsv:1: ads.example
+	// lists/more.lsv:1: tracker.example
+}
+
+// This example shows how to read several files in lexical order.
+func ExampleReadGlob() {
+	fsys := fstest.MapFS{
+		"conf.d/20-extra.lsv": {Data: []byte("tracker.example\n")},
+		"conf.d/10-base.lsv":  {Data: []byte("ads.example\nspam.example\n")},
+	}
+
+	records, err := ReadGlob(fsys, "conf.d/*.lsv")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, rec := range records {
+		fmt.Printf("%s:%d: %s\n", rec.File, rec.Line, rec.Value)
+	}
+	// Output:
+	// conf.d/10-base.lsv:1: ads.example
+	// conf.d/10-base.lsv:2: spam.example
+	// conf.d/20-extra.lsv:1: tracker.example
+}
@@ -0,0 +1,106 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// Format returns the canonical formatting of the LSV source using the
+// Parameters. It keeps all values, comments, and the grouping of lines
+// separated by blank lines, but normalizes the rest of the layout:
+//
+//   - indentation and trailing whitespace are removed, except for leading
+//     whitespace that is part of a value when TrimLeadingSpace is false;
+//   - values are only quoted and escaped when they need to be;
+//   - comment lines are written as the Comment character followed by a single
+//     space and inline comments in consecutive lines are aligned;
+//   - runs of blank lines are collapsed into a single blank line and blank
+//     lines at the start and end of the file are removed;
+//   - every line ends with \n, except for the lines inside a raw string
+//     literal that cannot be written by the Writer, which are kept as they
+//     are.
+//
+// Format is idempotent: formatting its output again returns the same output.
+// It returns the same errors as [ParseDocument].
+func Format(src []byte, p Parameters) ([]byte, error) {
+	d, err := ParseDocument(bytes.NewReader(src), p)
+	if err != nil {
+		return nil, err
+	}
+
+	var buff bytes.Buffer
+	w := NewCustomWriter(&buff, p)
+	w.AlignComments = true
+
+	var blank, written bool
+	for _, n := range d.Nodes {
+		if n.Kind == BlankNode {
+			blank = true
+			continue
+		}
+
+		// Only write a blank line between two other lines
+		if blank && written {
+			err = w.WriteBlankLine()
+			if err != nil {
+				return nil, err
+			}
+		}
+		blank, written = false, true
+
+		switch n.Kind {
+		case CommentNode:
+			err = w.writeBlockComment(n.Comment)
+		case SectionNode:
+			err = w.writeSection(n.Value, n.Comment)
+		default:
+			err = w.writeValue(n.Value, n.Comment, n.Value == "")
+		}
+		if errors.Is(err, ErrUnrepresentable) {
+			// Keep the source of a value or header the Writer cannot encode
+			err = w.writeSource(n.text)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = w.flush(); err != nil {
+		return nil, err
+	}
+
+	return buff.Bytes(), nil
+}
+
+// writeSource writes the source of a value with its trailing whitespace removed
+// and the line terminator of the Writer at the end. The indentation is only
+// removed when TrimLeadingSpace is set, since it is part of the value
+// otherwise. A value that spans several lines is a raw string literal, so the
+// line endings inside it are part of the value and are written as they are.
+func (w *Writer) writeSource(text string) error {
+	err := w.writeAligned()
+	if err != nil {
+		return err
+	}
+
+	text = strings.TrimRightFunc(text, unicode.IsSpace)
+	if w.TrimLeadingSpace {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+	}
+
+	_, err = w.w.WriteString(text)
+	if err != nil {
+		return err
+	}
+
+	return w.writeLineEnd()
+}
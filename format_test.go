@@ -0,0 +1,176 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var formatTests = []struct {
+	Name   string
+	Input  string
+	Output string
+}{
+	{"Empty", "", ""},
+	{"Blank", "\n  \n\t\n", ""},
+	{"Canonical", "a\nb\n", "a\nb\n"},
+	{"MissingLineEnd", "a\nb", "a\nb\n"},
+	{"CRLF", "a\r\nb\r\n", "a\nb\n"},
+	{"Indentation", "  a\n\tb\n", "a\nb\n"},
+	{"TrailingSpace", "a  \nb\t\n", "a\nb\n"},
+	{"UnnecessaryQuotes", "\"a\"\n\"b c\"\n", "a\nb c\n"},
+	{"NecessaryQuotes", "\" a\"\n\"\"\n\"b\nc\"\n", "\" a\"\n\"\"\n\"b\nc\"\n"},
+	{"QuotedComment", "\"a # b\"\n", "a \\# b\n"},
+	{"CommentSpacing", "#a\n  #   b  \n#\n", "# a\n# b\n#\n"},
+	{"InlineComments", "a #1\nbbb   # 2\n\ncc#3\n",
+		"a  \t# 1\nbbb\t# 2\n\ncc\t# 3\n"},
+	{"EmptyValueComment", "\"\" # Empty\n", "\"\"\t# Empty\n"},
+	{"BlankLines", "\n\na\n\n\n\n# b\n\n\nc\n\n", "a\n\n# b\n\nc\n"},
+	{"BlockResetsAlignment", "aaaa # 1\n# Block\nb # 2\n",
+		"aaaa\t# 1\n# Block\nb\t# 2\n"},
+	{"MultiLine", "  \"a\n  b\"   # Comment\n", "\"a\n  b\"\t# Comment\n"},
+}
+
+// Tests that Format produces the expected canonical output.
+func TestFormat(t *testing.T) {
+	for _, tt := range formatTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			out, err := Format([]byte(tt.Input), DefaultParameters())
+			if err != nil {
+				t.Fatalf("Failed to format: %+v", err)
+			}
+
+			if string(out) != tt.Output {
+				t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+					tt.Output, out)
+			}
+		})
+	}
+}
+
+// Tests that formatting the output of Format does not change it and that
+// Format does not change any values.
+func TestFormat_Idempotent(t *testing.T) {
+	inputs := append([]string{}, documentTests...)
+	for _, tt := range formatTests {
+		inputs = append(inputs, tt.Input)
+	}
+
+	for i, src := range inputs {
+		out, err := Format([]byte(src), DefaultParameters())
+		if err != nil {
+			t.Fatalf("Failed to format input %d: %+v", i, err)
+		}
+
+		out2, err := Format(out, DefaultParameters())
+		if err != nil {
+			t.Fatalf("Failed to format output %d: %+v", i, err)
+		}
+		if !bytes.Equal(out, out2) {
+			t.Errorf("Formatting output %d changed it."+
+				"\nexpected: %q\nreceived: %q", i, out, out2)
+		}
+
+		expected, err := Unmarshal([]byte(src))
+		if err != nil {
+			t.Fatalf("Failed to read input %d: %+v", i, err)
+		}
+		values, err := Unmarshal(out)
+		if err != nil {
+			t.Fatalf("Failed to read output %d: %+v", i, err)
+		}
+		if !reflect.DeepEqual(expected, values) {
+			t.Errorf("Format changed the values of input %d."+
+				"\nexpected: %q\nreceived: %q", i, expected, values)
+		}
+	}
+}
+
+// Tests that Format keeps the source of values that the Writer cannot encode,
+// including the line endings inside a raw string literal and, when
+// TrimLeadingSpace is false, leading whitespace, so that ReadAll returns the
+// same values before and after formatting and formatting again changes nothing.
+func TestFormat_Unrepresentable(t *testing.T) {
+	type test struct {
+		Name             string
+		Input            string
+		TrimLeadingSpace bool
+	}
+
+	tests := []test{
+		{"LF", "\"x\\\"\n\r\n\"open # c\n# comment\nend\"\n", true},
+		{"CRLF", "\"x\\\"\r\n\r\n\"open # c\r\n# comment\r\nend\"\r\n", true},
+		{"LeadingSpace", "\t é\\\n", false},
+		{"LeadingCR", "\r\"a \\\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			p := DefaultParameters()
+			p.TrimLeadingSpace = tt.TrimLeadingSpace
+			expected, err := NewCustomReader(
+				strings.NewReader(tt.Input), p).ReadAll()
+			if err != nil {
+				t.Fatalf("Failed to read input: %+v", err)
+			}
+
+			out, err := Format([]byte(tt.Input), p)
+			if err != nil {
+				t.Fatalf("Failed to format input: %+v", err)
+			}
+			values, err := NewCustomReader(bytes.NewReader(out), p).ReadAll()
+			if err != nil {
+				t.Fatalf("Failed to read output %q: %+v", out, err)
+			}
+			if !reflect.DeepEqual(expected, values) {
+				t.Errorf("Format changed the values."+
+					"\nexpected: %q\nreceived: %q", expected, values)
+			}
+
+			out2, err := Format(out, p)
+			if err != nil {
+				t.Fatalf("Failed to format output %q: %+v", out, err)
+			}
+			if !bytes.Equal(out, out2) {
+				t.Errorf("Formatting the output changed it."+
+					"\nexpected: %q\nreceived: %q", out, out2)
+			}
+		})
+	}
+}
+
+// Tests that Format uses the custom Parameters.
+func TestFormat_CustomParameters(t *testing.T) {
+	p := Parameters{
+		Comment: ';', Raw: '\'', Escape: '/', TrimLeadingSpace: true}
+	src := "  'a'  ;Comment\n'b;c'\n"
+	expected := "a\t; Comment\nb/;c\n"
+
+	out, err := Format([]byte(src), p)
+	if err != nil {
+		t.Fatalf("Failed to format: %+v", err)
+	}
+
+	if string(out) != expected {
+		t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+			expected, out)
+	}
+}
+
+// Tests that Format returns a ParseError for an invalid source.
+func TestFormat_ParseError(t *testing.T) {
+	_, err := Format([]byte("a\n\"b\n"), DefaultParameters())
+	var pe *ParseError
+	if !errors.As(err, &pe) || !errors.Is(err, ErrNoClosingRaw) {
+		t.Errorf("Unexpected error: %+v", err)
+	}
+}
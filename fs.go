@@ -0,0 +1,106 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"io"
+	"io/fs"
+	"sort"
+)
+
+// ReadFile reads all the values from the file with the name in the file system
+// using the Parameters. It returns the same errors as [Reader.ReadAll], with
+// the name of the file in any ParseError, and any error from opening the file.
+func ReadFile(fsys fs.FS, name string, p Parameters) ([]string, error) {
+	var values []string
+	err := readFile(fsys, name, p, func(_ *Reader, rec Record) {
+		values = append(values, rec.Value)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// ReadGlob reads all the values from the files in the file system with names
+// matching the pattern using the default Parameters. It returns the same
+// results as [ReadGlobParams].
+func ReadGlob(fsys fs.FS, pattern string) ([]SourceRecord, error) {
+	return ReadGlobParams(fsys, pattern, DefaultParameters())
+}
+
+// ReadGlobParams reads all the values from the files in the file system with
+// names matching the pattern using the Parameters. The pattern syntax is the
+// same as in [fs.Glob]. Directories matching the pattern are skipped.
+//
+// The files are read in lexical order of their names, which makes it possible
+// to split a list into several files, such as conf.d/10-base.lsv and
+// conf.d/20-extra.lsv, and read them back in a deterministic order. Each value
+// is returned with the name of its file and its line in that file. If no files
+// match, ReadGlobParams returns no values and no error.
+//
+// ReadGlobParams returns the same errors as [ReadFile], and
+// [path.ErrBadPattern] if the pattern is malformed.
+func ReadGlobParams(
+	fsys fs.FS, pattern string, p Parameters) ([]SourceRecord, error) {
+	if !p.Verify() {
+		return nil, ErrInvalidParams
+	}
+
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	var records []SourceRecord
+	for _, name := range names {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return nil, err
+		} else if info.IsDir() {
+			continue
+		}
+
+		err = readFile(fsys, name, p, func(r *Reader, rec Record) {
+			records = append(records, SourceRecord{rec, name, r.start.Line})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return records, nil
+}
+
+// readFile reads every record from the file with the name in the file system
+// and calls fn with the Reader and the record.
+func readFile(fsys fs.FS, name string, p Parameters,
+	fn func(r *Reader, rec Record)) error {
+	if !p.Verify() {
+		return ErrInvalidParams
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := NewCustomReader(f, p)
+	r.FileName = name
+	for {
+		rec, err := r.readRecord()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		fn(r, rec)
+	}
+}
@@ -0,0 +1,173 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// Tests that ReadFile reads the values of a file using the Parameters.
+func TestReadFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"d/a.lsv": {Data: []byte("a ; one\n\n'b\nc'\n")},
+	}
+	p := Parameters{Comment: ';', Raw: '\'', Escape: '\\'}
+
+	values, err := ReadFile(fsys, "d/a.lsv", p)
+	if err != nil {
+		t.Fatalf("Failed to read file: %+v", err)
+	}
+	if expected := []string{"a", "b\nc"}; !reflect.DeepEqual(expected, values) {
+		t.Errorf("Unexpected values.\nexpected: %q\nreceived: %q",
+			expected, values)
+	}
+}
+
+// Tests that ReadFile returns the expected errors.
+func TestReadFile_Error(t *testing.T) {
+	fsys := fstest.MapFS{"a.lsv": {Data: []byte("a\n\"b\n")}}
+
+	_, err := ReadFile(fsys, "a.lsv", DefaultParameters())
+	var pe *ParseError
+	if !errors.As(err, &pe) || !errors.Is(err, ErrNoClosingRaw) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrNoClosingRaw, err)
+	} else if pe.File != "a.lsv" {
+		t.Errorf("Unexpected file.\nexpected: %s\nreceived: %s",
+			"a.lsv", pe.File)
+	}
+
+	_, err = ReadFile(fsys, "b.lsv", DefaultParameters())
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			fs.ErrNotExist, err)
+	}
+
+	_, err = ReadFile(fsys, "a.lsv", Parameters{})
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrInvalidParams, err)
+	}
+}
+
+// Tests that ReadGlob reads the matching files in lexical order and returns
+// the file and line of each value.
+func TestReadGlob(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf.d/20-b.lsv":   {Data: []byte("\n# c\nb\n")},
+		"conf.d/10-a.lsv":   {Data: []byte("a1\na2 # x\n")},
+		"conf.d/15-e.lsv":   {Data: []byte("# empty\n")},
+		"conf.d/30.txt":     {Data: []byte("ignored\n")},
+		"conf.d/40.lsv/f":   {Data: []byte("directory\n")},
+		"other/a-b/x.lsv":   {Data: []byte("ab\n")},
+		"other/a/x.lsv":     {Data: []byte("a\n")},
+		"other/a-b/y.notes": {Data: []byte("ignored\n")},
+	}
+
+	records, err := ReadGlob(fsys, "conf.d/*.lsv")
+	if err != nil {
+		t.Fatalf("Failed to read glob: %+v", err)
+	}
+	expected := []SourceRecord{
+		{Record{ValueComment{"a1", ""}, false, nil}, "conf.d/10-a.lsv", 1},
+		{Record{ValueComment{"a2", "x"}, false, nil}, "conf.d/10-a.lsv", 2},
+		{Record{ValueComment{"b", ""}, false, []string{"c"}},
+			"conf.d/20-b.lsv", 3},
+	}
+	if !reflect.DeepEqual(expected, records) {
+		t.Errorf("Unexpected records.\nexpected: %+v\nreceived: %+v",
+			expected, records)
+	}
+
+	// Names are sorted as a whole and not by directory
+	records, err = ReadGlob(fsys, "other/*/*.lsv")
+	if err != nil {
+		t.Fatalf("Failed to read glob: %+v", err)
+	}
+	var files []string
+	for _, rec := range records {
+		files = append(files, rec.File)
+	}
+	expectedFiles := []string{"other/a-b/x.lsv", "other/a/x.lsv"}
+	if !reflect.DeepEqual(expectedFiles, files) {
+		t.Errorf("Unexpected files.\nexpected: %q\nreceived: %q",
+			expectedFiles, files)
+	}
+
+	records, err = ReadGlob(fsys, "none/*.lsv")
+	if err != nil || records != nil {
+		t.Errorf("Unexpected result for no matches: %+v, %v", records, err)
+	}
+}
+
+// Tests that ReadGlobParams reads files from a directory on disk using
+// os.DirFS in the same way as from a fstest.MapFS.
+func TestReadGlobParams_DirFS(t *testing.T) {
+	files := map[string]string{
+		"b.lsv": "b ; two\n",
+		"a.lsv": "a ; one\n",
+	}
+	dir := t.TempDir()
+	mapFS := fstest.MapFS{}
+	for name, data := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600)
+		if err != nil {
+			t.Fatalf("Failed to write %s: %+v", name, err)
+		}
+		mapFS[name] = &fstest.MapFile{Data: []byte(data)}
+	}
+
+	p := Parameters{Comment: ';', Raw: '"', Escape: '\\'}
+	fromDisk, err := ReadGlobParams(os.DirFS(dir), "*.lsv", p)
+	if err != nil {
+		t.Fatalf("Failed to read glob from disk: %+v", err)
+	}
+	fromMap, err := ReadGlobParams(mapFS, "*.lsv", p)
+	if err != nil {
+		t.Fatalf("Failed to read glob from map: %+v", err)
+	}
+	if !reflect.DeepEqual(fromDisk, fromMap) || len(fromDisk) != 2 ||
+		fromDisk[0].Value != "a" || fromDisk[1].Comment != "two" {
+		t.Errorf("Unexpected records.\ndisk: %+v\nmap:  %+v",
+			fromDisk, fromMap)
+	}
+}
+
+// Tests that ReadGlobParams returns the expected errors.
+func TestReadGlobParams_Error(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.lsv": {Data: []byte("a\n")},
+		"b.lsv": {Data: []byte("\"b\n")},
+	}
+
+	_, err := ReadGlob(fsys, "*.lsv")
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.File != "b.lsv" {
+		t.Errorf("Unexpected error.\nexpected: ParseError in b.lsv"+
+			"\nreceived: %v", err)
+	}
+
+	_, err = ReadGlob(fsys, "[")
+	if !errors.Is(err, path.ErrBadPattern) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			path.ErrBadPattern, err)
+	}
+
+	_, err = ReadGlobParams(fsys, "*.lsv", Parameters{})
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrInvalidParams, err)
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/jonow/lsv
 
-go 1.17
+go 1.18
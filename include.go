@@ -0,0 +1,265 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Include defaults.
+const (
+	// DefaultIncludeDirective is the default name of the include directive.
+	DefaultIncludeDirective = "include"
+
+	// DefaultMaxIncludeDepth is the default maximum depth of nested includes.
+	DefaultMaxIncludeDepth = 16
+)
+
+// Include errors.
+var (
+	// ErrIncludePath is returned when an include directive has no path or its
+	// path is outside the file system.
+	ErrIncludePath = errors.New("invalid include path")
+
+	// ErrIncludeCycle is returned when a file includes itself directly or
+	// through other files.
+	ErrIncludeCycle = errors.New("include cycle")
+
+	// ErrIncludeDepth is returned when includes are nested deeper than the
+	// maximum depth.
+	ErrIncludeDepth = errors.New("maximum include depth exceeded")
+)
+
+// errInclude is returned by Reader.readRecord when it reads an include
+// directive.
+var errInclude = errors.New("include directive")
+
+// SourceRecord is a Record along with the file and line it was read from.
+type SourceRecord struct {
+	Record
+
+	// File is the name of the file in the file system and Line is the line
+	// where the value starts.
+	File string
+	Line int
+}
+
+// IncludeReader reads values from a LSV file and the files it includes. An
+// include directive is a comment line where the Comment character is directly
+// followed by the Directive and a path, such as:
+//
+//	#include other.lsv
+//
+// The values of the included file are read in place of the directive. The path
+// is relative to the directory of the including file, or to the root of the
+// file system if it starts with a slash, and must not leave the file system.
+// Files are opened from the file system as they are reached.
+//
+// An include directive that would read a file that is already being read
+// returns a ParseError wrapping ErrIncludeCycle, and one nested deeper than
+// MaxDepth returns a ParseError wrapping ErrIncludeDepth. Both, and a
+// ParseError wrapping the error returned when opening the included file, are
+// reported at the position of the directive.
+//
+// The exported fields can be changed to customize the details before the first
+// call to [IncludeReader.Read] or [IncludeReader.ReadAll].
+type IncludeReader struct {
+	Parameters
+
+	// Directive is the name of the include directive. It defaults to
+	// DefaultIncludeDirective.
+	Directive string
+
+	// MaxDepth is the maximum number of nested includes. It defaults to
+	// DefaultMaxIncludeDepth.
+	MaxDepth int
+
+	fsys fs.FS
+	name string
+
+	// files contains the file that includes each following file, starting with
+	// the file named name. opened is true once that file has been opened.
+	files  []*includeFile
+	opened bool
+}
+
+// includeFile is a file being read by an IncludeReader.
+type includeFile struct {
+	f fs.File
+	r *Reader
+}
+
+// NewIncludeReader returns a new IncludeReader that reads the file with the
+// name from the file system.
+func NewIncludeReader(fsys fs.FS, name string) *IncludeReader {
+	return NewCustomIncludeReader(fsys, name, DefaultParameters())
+}
+
+// NewCustomIncludeReader returns a new IncludeReader that reads the file with
+// the name from the file system with custom LSV parameters.
+func NewCustomIncludeReader(
+	fsys fs.FS, name string, p Parameters) *IncludeReader {
+	return &IncludeReader{
+		Parameters: p,
+		Directive:  DefaultIncludeDirective,
+		MaxDepth:   DefaultMaxIncludeDepth,
+		fsys:       fsys,
+		name:       name,
+	}
+}
+
+// ReadAll reads all the remaining values from the file and the files it
+// includes. A successful call returns err == nil, not err == io.EOF. Because
+// ReadAll is defined to read until EOF, it does not treat end of file as an
+// error to be reported.
+func (ir *IncludeReader) ReadAll() ([]string, error) {
+	var values []string
+	for {
+		value, err := ir.Read()
+		if err == io.EOF {
+			return values, nil
+		} else if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+}
+
+// Read reads one value from the file or the files it includes. It returns the
+// same errors as [Reader.Read] and the include errors described by
+// [IncludeReader]. If there is no data left to be read, Read returns io.EOF.
+func (ir *IncludeReader) Read() (string, error) {
+	rec, err := ir.ReadRecord()
+	return rec.Value, err
+}
+
+// ReadRecord reads one value along with its comments, the name of the file it
+// was read from, and its line in that file. It returns the same errors as
+// [IncludeReader.Read].
+func (ir *IncludeReader) ReadRecord() (SourceRecord, error) {
+	if !ir.Verify() {
+		return SourceRecord{}, ErrInvalidParams
+	}
+
+	if !ir.opened {
+		ir.opened = true
+		if err := ir.open(ir.name); err != nil {
+			return SourceRecord{}, err
+		}
+	}
+
+	for len(ir.files) > 0 {
+		top := ir.files[len(ir.files)-1]
+		rec, err := top.r.readRecord()
+		switch {
+		case err == nil:
+			return SourceRecord{rec, top.r.FileName, top.r.start.Line}, nil
+		case err == io.EOF:
+			ir.files = ir.files[:len(ir.files)-1]
+			if err = top.f.Close(); err != nil {
+				return SourceRecord{}, err
+			}
+		case errors.Is(err, errInclude):
+			if err = ir.include(top.r); err != nil {
+				return SourceRecord{}, err
+			}
+		default:
+			return SourceRecord{}, err
+		}
+	}
+
+	return SourceRecord{}, io.EOF
+}
+
+// Close closes every file that is still open. It only needs to be called if
+// reading stops before io.EOF is returned.
+func (ir *IncludeReader) Close() error {
+	var err error
+	for len(ir.files) > 0 {
+		top := ir.files[len(ir.files)-1]
+		ir.files = ir.files[:len(ir.files)-1]
+		if closeErr := top.f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// open opens the file with the name and reads from it until it is closed.
+func (ir *IncludeReader) open(name string) error {
+	f, err := ir.fsys.Open(name)
+	if err != nil {
+		return err
+	}
+
+	r := NewCustomReader(f, ir.Parameters)
+	r.FileName = name
+	r.directive = ir.Directive
+	ir.files = append(ir.files, &includeFile{f, r})
+	return nil
+}
+
+// include opens the file included by the directive most recently read by r.
+func (ir *IncludeReader) include(r *Reader) error {
+	pos := r.includePos
+	name, ok := includeName(r.FileName, r.includePath)
+	if !ok {
+		return r.newParseError(pos.Line, pos, ErrIncludePath)
+	}
+
+	for _, file := range ir.files {
+		if file.r.FileName == name {
+			return r.newParseError(pos.Line, pos, ErrIncludeCycle)
+		}
+	}
+	if len(ir.files) > ir.MaxDepth {
+		return r.newParseError(pos.Line, pos, ErrIncludeDepth)
+	}
+
+	if err := ir.open(name); err != nil {
+		return r.newParseError(pos.Line, pos, err)
+	}
+	return nil
+}
+
+// includeName returns the name in the file system of the file at the path
+// included from the file with the name from. It returns false if the path is
+// empty or outside the file system.
+func includeName(from, target string) (string, bool) {
+	var name string
+	if strings.HasPrefix(target, "/") {
+		name = path.Clean(target[1:])
+	} else {
+		name = path.Join(path.Dir(from), target)
+	}
+	return name, target != "" && name != "." && fs.ValidPath(name)
+}
+
+// readInclude determines if the comment is an include directive. If it is, the
+// path and position of the directive are saved so that the file can be
+// included.
+func (r *Reader) readInclude(comment string, pos Position) bool {
+	if r.directive == "" || !strings.HasPrefix(comment, r.directive) {
+		return false
+	}
+
+	rest := comment[len(r.directive):]
+	if c, _ := utf8.DecodeRuneInString(rest); rest != "" &&
+		!unicode.IsSpace(c) {
+		return false
+	}
+
+	r.includePath, r.includePos = r.keep(strings.TrimSpace(rest)), pos
+	return true
+}
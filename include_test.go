@@ -0,0 +1,230 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// countingFS is a file system that counts the files that are open.
+type countingFS struct {
+	fs.FS
+	open int
+}
+
+func (c *countingFS) Open(name string) (fs.File, error) {
+	f, err := c.FS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	c.open++
+	return &countingFile{f, c}, nil
+}
+
+// countingFile is a file opened by a countingFS.
+type countingFile struct {
+	fs.File
+	fs *countingFS
+}
+
+func (f *countingFile) Close() error {
+	f.fs.open--
+	return f.File.Close()
+}
+
+// Tests that IncludeReader reads the values of included files in place of the
+// directives and records the file and line of each value.
+func TestIncludeReader_ReadRecord(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.lsv": {Data: []byte(
+			"a\n# Above\n#include lists/b.lsv\n# include x\n#includes\nc\n")},
+		"lists/b.lsv": {Data: []byte(
+			"\n  b1 # one\n#include\tsub/d.lsv \n#include /e.lsv\nb2\n")},
+		"lists/sub/d.lsv": {Data: []byte("\"d\"\n")},
+		"e.lsv":           {Data: []byte("# only comments\n")},
+	}
+
+	expected := []SourceRecord{
+		{Record{ValueComment{"a", ""}, false, nil}, "main.lsv", 1},
+		{Record{ValueComment{"b1", "one"}, false, nil}, "lists/b.lsv", 2},
+		{Record{ValueComment{"d", ""}, true, nil}, "lists/sub/d.lsv", 1},
+		{Record{ValueComment{"b2", ""}, false, nil}, "lists/b.lsv", 5},
+		{Record{ValueComment{"c", ""}, false,
+			[]string{"include x", "includes"}}, "main.lsv", 6},
+	}
+
+	for _, strict := range []bool{false, true} {
+		p := DefaultParameters()
+		p.Strict = strict
+		cfs := &countingFS{FS: fsys}
+		ir := NewCustomIncludeReader(cfs, "main.lsv", p)
+
+		var records []SourceRecord
+		for {
+			rec, err := ir.ReadRecord()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("Failed to read record (strict %t): %+v", strict, err)
+			}
+			records = append(records, rec)
+		}
+
+		if !reflect.DeepEqual(expected, records) {
+			t.Errorf("Unexpected records (strict %t).\nexpected: %+v"+
+				"\nreceived: %+v", strict, expected, records)
+		}
+		if cfs.open != 0 {
+			t.Errorf("%d files still open after EOF.", cfs.open)
+		}
+	}
+}
+
+// Tests that IncludeReader uses a custom Directive and Comment character.
+func TestIncludeReader_Directive(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.lsv": {Data: []byte("a\n;use b.lsv\n;include c.lsv\n")},
+		"b.lsv": {Data: []byte("b\n")},
+	}
+
+	ir := NewCustomIncludeReader(fsys, "a.lsv",
+		Parameters{Comment: ';', Raw: '"', Escape: '\\'})
+	ir.Directive = "use"
+	values, err := ir.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read: %+v", err)
+	}
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(expected, values) {
+		t.Errorf("Unexpected values.\nexpected: %q\nreceived: %q",
+			expected, values)
+	}
+}
+
+// Tests that IncludeReader returns a ParseError at the position of the
+// directive for each include error.
+func TestIncludeReader_ReadAll_Error(t *testing.T) {
+	type test struct {
+		Name string
+		FS   fstest.MapFS
+		File string
+		Line int
+		Err  error
+	}
+
+	tests := []test{
+		{"Self", fstest.MapFS{
+			"a.lsv": {Data: []byte("a\n#include a.lsv\n")},
+		}, "a.lsv", 2, ErrIncludeCycle},
+		{"Cycle", fstest.MapFS{
+			"a.lsv":   {Data: []byte("#include d/b.lsv\n")},
+			"d/b.lsv": {Data: []byte("b\n\n#include /a.lsv\n")},
+		}, "d/b.lsv", 3, ErrIncludeCycle},
+		{"Depth", fstest.MapFS{
+			"a.lsv": {Data: []byte("#include b.lsv\n")},
+			"b.lsv": {Data: []byte("#include c.lsv\n")},
+			"c.lsv": {Data: []byte("#include d.lsv\n")},
+			"d.lsv": {Data: []byte("d\n")},
+		}, "c.lsv", 1, ErrIncludeDepth},
+		{"NotExist", fstest.MapFS{
+			"a.lsv": {Data: []byte("a\n  #include b.lsv\n")},
+		}, "a.lsv", 2, fs.ErrNotExist},
+		{"Empty", fstest.MapFS{
+			"a.lsv": {Data: []byte("#include  \n")},
+		}, "a.lsv", 1, ErrIncludePath},
+		{"Outside", fstest.MapFS{
+			"d/a.lsv": {Data: []byte("#include ../../b.lsv\n")},
+		}, "d/a.lsv", 1, ErrIncludePath},
+		{"ParseError", fstest.MapFS{
+			"d/a.lsv": {Data: []byte("#include b.lsv\n")},
+			"d/b.lsv": {Data: []byte("b\n\"c\n")},
+		}, "d/b.lsv", 3, ErrNoClosingRaw},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			root := "a.lsv"
+			if _, exists := tt.FS[root]; !exists {
+				root = "d/a.lsv"
+			}
+			cfs := &countingFS{FS: tt.FS}
+			ir := NewIncludeReader(cfs, root)
+			ir.MaxDepth = 2
+			_, err := ir.ReadAll()
+
+			var pe *ParseError
+			if !errors.As(err, &pe) || !errors.Is(err, tt.Err) {
+				t.Fatalf("Unexpected error.\nexpected: %v\nreceived: %+v",
+					tt.Err, err)
+			}
+			if pe.File != tt.File || pe.Line != tt.Line {
+				t.Errorf("Unexpected position.\nexpected: %s:%d"+
+					"\nreceived: %s:%d", tt.File, tt.Line, pe.File, pe.Line)
+			}
+
+			if err = ir.Close(); err != nil {
+				t.Errorf("Failed to close: %+v", err)
+			}
+			if cfs.open != 0 {
+				t.Errorf("%d files still open after Close.", cfs.open)
+			}
+		})
+	}
+}
+
+// Tests that IncludeReader returns the error from opening the first file and
+// ErrInvalidParams for invalid Parameters.
+func TestIncludeReader_Read_Error(t *testing.T) {
+	_, err := NewIncludeReader(fstest.MapFS{}, "a.lsv").Read()
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			fs.ErrNotExist, err)
+	}
+
+	ir := NewCustomIncludeReader(fstest.MapFS{}, "a.lsv", Parameters{})
+	_, err = ir.Read()
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrInvalidParams, err)
+	}
+}
+
+// Tests that includeName resolves paths relative to the including file and
+// rejects paths outside the file system.
+func Test_includeName(t *testing.T) {
+	type test struct {
+		From, Target, Name string
+		OK                 bool
+	}
+
+	tests := []test{
+		{"a.lsv", "b.lsv", "b.lsv", true},
+		{"d/a.lsv", "b.lsv", "d/b.lsv", true},
+		{"d/a.lsv", "../b.lsv", "b.lsv", true},
+		{"d/a.lsv", "./e/../b.lsv", "d/b.lsv", true},
+		{"d/a.lsv", "/b.lsv", "b.lsv", true},
+		{"d/a.lsv", "/e/b.lsv", "e/b.lsv", true},
+		{"a.lsv", "../b.lsv", "../b.lsv", false},
+		{"a.lsv", "", ".", false},
+		{"d/a.lsv", "..", ".", false},
+		{"a.lsv", "/", ".", false},
+	}
+
+	for _, tt := range tests {
+		name, ok := includeName(tt.From, tt.Target)
+		if name != tt.Name || ok != tt.OK {
+			t.Errorf("Unexpected result for %q from %q."+
+				"\nexpected: %q, %t\nreceived: %q, %t",
+				tt.Target, tt.From, tt.Name, tt.OK, name, ok)
+		}
+	}
+}
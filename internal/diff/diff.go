@@ -0,0 +1,218 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+// Package diff computes line-based differences between two texts and formats
+// them as unified diffs.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around each change.
+const context = 3
+
+// opKind is the kind of an edit operation.
+type opKind byte
+
+// Edit operations.
+const (
+	equal  opKind = ' '
+	remove opKind = '-'
+	insert opKind = '+'
+)
+
+// op is a single line of an edit script. a and b are the indexes of the line
+// in the old and new texts or, if the line is not in that text, the index it
+// would have been inserted at.
+type op struct {
+	kind opKind
+	a, b int
+}
+
+// Diff returns a unified diff of the old and new texts, labelled with their
+// names. It returns nil if the texts are equal.
+func Diff(oldName string, old []byte, newName string, new []byte) []byte {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+
+	a, b := splitLines(string(old)), splitLines(string(new))
+	ops := editScript(a, b)
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	// Each hunk covers a run of changes separated by fewer than 2*context
+	// unchanged lines, plus up to context unchanged lines on each side
+	for i := 0; i < len(ops); {
+		if ops[i].kind == equal {
+			i++
+			continue
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == equal {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end += min(context, run-end)
+				break
+			}
+			end = run
+		}
+
+		writeHunk(&out, a, b, ops[start:end])
+		i = end
+	}
+
+	return out.Bytes()
+}
+
+// writeHunk writes the hunk header and lines for the operations.
+func writeHunk(out *bytes.Buffer, a, b []string, ops []op) {
+	aCount := len(ops) - countKind(ops, insert)
+	bCount := len(ops) - countKind(ops, remove)
+	fmt.Fprintf(out, "@@ -%s +%s @@\n",
+		hunkRange(ops[0].a, aCount), hunkRange(ops[0].b, bCount))
+
+	for _, o := range ops {
+		var line string
+		if o.kind == insert {
+			line = b[o.b]
+		} else {
+			line = a[o.a]
+		}
+		out.WriteByte(byte(o.kind))
+		out.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the start line and number of lines of a hunk. The start
+// line is one-based or, for an empty range, the line before the range.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	} else if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// countKind returns the number of operations of the kind.
+func countKind(ops []op, kind opKind) int {
+	var n int
+	for _, o := range ops {
+		if o.kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// splitLines splits s after each newline.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// editScript returns the shortest list of operations that turns a into b
+// using the Myers difference algorithm.
+func editScript(a, b []string) []op {
+	n, m := len(a), len(b)
+	max := n + m
+
+	// v[max+k] is the furthest x reached on diagonal k. trace[d] holds the
+	// diagonals -d through d of v at the start of step d.
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int{}, v[max-d:max+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[max+k-1] < v[max+k+1]) {
+				x = v[max+k+1]
+			} else {
+				x = v[max+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[max+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk back through the trace to recover the operations
+	var ops []op
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && prev[d+k-1] < prev[d+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := prev[d+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			ops = append(ops, op{equal, x, y})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, op{insert, x, y})
+		} else {
+			x--
+			ops = append(ops, op{remove, x, y})
+		}
+	}
+	for x > 0 {
+		x, y = x-1, y-1
+		ops = append(ops, op{equal, x, y})
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// min returns the smaller of a and b.
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,76 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests that Diff produces the same unified diffs as diff -u.
+func TestDiff(t *testing.T) {
+	type test struct {
+		Name     string
+		Old, New string
+		Expected string
+	}
+
+	alphabet := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	tests := []test{{
+		Name:     "Equal",
+		Old:      "a\nb\n",
+		New:      "a\nb\n",
+		Expected: "",
+	}, {
+		Name: "TwoHunks",
+		Old:  alphabet,
+		New:  strings.Replace(alphabet, "b", "B", 1) + "n",
+		Expected: "--- old\n+++ new\n" +
+			"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+			"@@ -11,3 +11,4 @@\n k\n l\n m\n+n\n" +
+			"\\ No newline at end of file\n",
+	}, {
+		Name: "OneHunk",
+		Old:  alphabet,
+		New:  strings.Replace(alphabet, "g\n", "", 1) + "n\n",
+		Expected: "--- old\n+++ new\n" +
+			"@@ -4,10 +4,10 @@\n d\n e\n f\n-g\n h\n i\n j\n k\n l\n m\n+n\n",
+	}, {
+		Name:     "FromEmpty",
+		Old:      "",
+		New:      "a\nb\n",
+		Expected: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+	}, {
+		Name:     "ToEmpty",
+		Old:      "a\n",
+		New:      "",
+		Expected: "--- old\n+++ new\n@@ -1 +0,0 @@\n-a\n",
+	}, {
+		Name:     "InsertMiddle",
+		Old:      "a\nc\n",
+		New:      "a\nb\nc\n",
+		Expected: "--- old\n+++ new\n@@ -1,2 +1,3 @@\n a\n+b\n c\n",
+	}, {
+		Name: "MissingNewline",
+		Old:  "a",
+		New:  "a\n",
+		Expected: "--- old\n+++ new\n@@ -1 +1 @@\n-a\n" +
+			"\\ No newline at end of file\n+a\n",
+	},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			out := Diff("old", []byte(tt.Old), "new", []byte(tt.New))
+			if string(out) != tt.Expected {
+				t.Errorf("Unexpected diff.\nexpected: %q\nreceived: %q",
+					tt.Expected, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+// Package paramflag defines the command-line flags shared by the LSV commands
+// for setting the lsv.Parameters.
+package paramflag
+
+import (
+	"errors"
+	"flag"
+	"unicode/utf8"
+
+	"github.com/jonow/lsv"
+)
+
+// Flags holds the values of the Parameters flags registered on a FlagSet.
+type Flags struct {
+	comment, raw, escape                runeValue
+	noTrim, strict, sequences, sections bool
+}
+
+// Register defines the --comment, --raw, --escape, --no-trim, --strict,
+// --escape-sequences, and --sections flags on the FlagSet. Their defaults are
+// the lsv.DefaultParameters.
+func Register(fs *flag.FlagSet) *Flags {
+	p := lsv.DefaultParameters()
+	f := &Flags{
+		comment: runeValue(p.Comment),
+		raw:     runeValue(p.Raw),
+		escape:  runeValue(p.Escape),
+		noTrim:  !p.TrimLeadingSpace,
+	}
+
+	fs.Var(&f.comment, "comment", "comment `character`")
+	fs.Var(&f.raw, "raw", "raw string literal quote `character`")
+	fs.Var(&f.escape, "escape", "escape `character`")
+	fs.BoolVar(&f.noTrim, "no-trim", f.noTrim,
+		"keep leading whitespace of unquoted values")
+	fs.BoolVar(&f.strict, "strict", p.Strict, "use the strict escape grammar")
+	fs.BoolVar(&f.sequences, "escape-sequences", p.EscapeSequences,
+		"decode C-style escape sequences in raw string literals")
+	fs.BoolVar(&f.sections, "sections", p.Sections,
+		"read lines starting with [ as section headers")
+
+	return f
+}
+
+// Parameters returns the Parameters set by the flags. It returns
+// lsv.ErrInvalidParams if they are not valid.
+func (f *Flags) Parameters() (lsv.Parameters, error) {
+	p := lsv.Parameters{
+		Comment:          rune(f.comment),
+		Raw:              rune(f.raw),
+		Escape:           rune(f.escape),
+		TrimLeadingSpace: !f.noTrim,
+		Strict:           f.strict,
+		EscapeSequences:  f.sequences,
+		Sections:         f.sections,
+	}
+	if !p.Verify() {
+		return p, lsv.ErrInvalidParams
+	}
+	return p, nil
+}
+
+// runeValue is a flag.Value holding a single character.
+type runeValue rune
+
+// errNotSingleRune is returned when a rune flag is not a single character.
+var errNotSingleRune = errors.New("must be a single character")
+
+// String returns the character.
+func (r *runeValue) String() string {
+	return string(*r)
+}
+
+// Set sets the character to s, which must be a single character.
+func (r *runeValue) Set(s string) error {
+	c, size := utf8.DecodeRuneInString(s)
+	if size == 0 || size != len(s) || c == utf8.RuneError {
+		return errNotSingleRune
+	}
+	*r = runeValue(c)
+	return nil
+}
@@ -0,0 +1,86 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package paramflag
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/jonow/lsv"
+)
+
+// Tests that the flags produce the expected Parameters.
+func TestFlags_Parameters(t *testing.T) {
+	type test struct {
+		Name     string
+		Args     []string
+		Expected lsv.Parameters
+	}
+
+	tests := []test{
+		{"Default", nil, lsv.DefaultParameters()},
+		{"Custom", []string{"--comment", ";", "-raw='", "--escape=/",
+			"--no-trim"}, lsv.Parameters{Comment: ';', Raw: '\'', Escape: '/'}},
+		{"Unicode", []string{"--comment=§"}, lsv.Parameters{
+			Comment: '§', Raw: '"', Escape: '\\', TrimLeadingSpace: true}},
+		{"Strict", []string{"--strict"}, lsv.Parameters{Comment: '#',
+			Raw: '"', Escape: '\\', TrimLeadingSpace: true, Strict: true}},
+		{"EscapeSequences", []string{"--escape-sequences"}, lsv.Parameters{
+			Comment: '#', Raw: '"', Escape: '\\', TrimLeadingSpace: true,
+			EscapeSequences: true}},
+		{"Sections", []string{"--sections"}, lsv.Parameters{Comment: '#',
+			Raw: '"', Escape: '\\', TrimLeadingSpace: true, Sections: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			f := Register(fs)
+			if err := fs.Parse(tt.Args); err != nil {
+				t.Fatalf("Failed to parse flags: %+v", err)
+			}
+
+			p, err := f.Parameters()
+			if err != nil {
+				t.Fatalf("Failed to get parameters: %+v", err)
+			}
+			if p != tt.Expected {
+				t.Errorf("Unexpected parameters.\nexpected: %+v\nreceived: %+v",
+					tt.Expected, p)
+			}
+		})
+	}
+}
+
+// Tests that a flag that is not a single character is rejected.
+func TestRegister_InvalidRune(t *testing.T) {
+	for _, arg := range []string{"--comment=", "--raw=ab", "--escape=\xff"} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		Register(fs)
+		if err := fs.Parse([]string{arg}); err == nil {
+			t.Errorf("No error for %q.", arg)
+		}
+	}
+}
+
+// Tests that Flags.Parameters returns lsv.ErrInvalidParams when the characters
+// are not valid parameters.
+func TestFlags_Parameters_Invalid(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	f := Register(fs)
+	if err := fs.Parse([]string{"--raw=#"}); err != nil {
+		t.Fatalf("Failed to parse flags: %+v", err)
+	}
+
+	if _, err := f.Parameters(); !errors.Is(err, lsv.ErrInvalidParams) {
+		t.Errorf("Unexpected error: %+v", err)
+	}
+}
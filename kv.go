@@ -0,0 +1,442 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Key/value errors.
+var (
+	// ErrNoSeparator is returned when a line of a key/value file has a key but
+	// no Separator character after it.
+	ErrNoSeparator = errors.New("key without separator")
+
+	// ErrDuplicateKey is returned when a key appears more than once.
+	ErrDuplicateKey = errors.New("duplicate key")
+)
+
+// KeyValue is a single key/value pair.
+type KeyValue struct {
+	Key, Value string
+}
+
+// KVRecord is a single key/value pair read from an LSV-KV file along with the
+// comments attached to it. It can be written back using
+// [KVWriter.WriteRecord].
+type KVRecord struct {
+	// Key is the key of the pair.
+	Key string
+
+	// Record contains the value and its comments. Quoted is true if the value
+	// was written as a raw string literal.
+	Record
+}
+
+// KVReader reads key/value pairs from an LSV-KV file.
+//
+// An LSV-KV file is an LSV file in which every value is preceded by a key and
+// the Separator character, such as "key = value". Comments, raw string
+// literals, and escaping work the same as in an LSV file for the value.
+//
+// A key is either unquoted, in which case it ends at the first unescaped
+// Separator character and its trailing whitespace is trimmed, or a raw string
+// literal on a single line. In both, the Escape character followed by the
+// Escape, Comment, Raw, or Separator character, or the opening section
+// character if Sections is set, is replaced by the second character. In strict
+// mode, any other escape is an error; otherwise, it is read literally. If
+// EscapeSequences is set, escape sequences are decoded in quoted keys.
+// Whitespace after the Separator character is ignored and an empty value is
+// allowed.
+//
+// The exported fields can be changed to customize the details before the first
+// call to [KVReader.Read] or [KVReader.ReadAll].
+type KVReader struct {
+	Parameters
+
+	// FileName is the name of the file being read. If set, it is included in
+	// any returned ParseError.
+	FileName string
+
+	r *Reader
+
+	// seen maps each section and key read to the position of the first
+	// occurrence of the key in the section.
+	seen map[[2]string]Position
+}
+
+// NewKVReader returns a new KVReader that reads from r.
+func NewKVReader(r io.Reader) *KVReader {
+	return NewCustomKVReader(r, DefaultParameters())
+}
+
+// NewCustomKVReader returns a new KVReader that reads from r with custom LSV
+// parameters.
+func NewCustomKVReader(r io.Reader, p Parameters) *KVReader {
+	return &KVReader{
+		Parameters: p,
+		r:          &Reader{r: newBufferedLines(r), kv: true},
+		seen:       make(map[[2]string]Position),
+	}
+}
+
+// ReadAll reads all the remaining pairs from r in order. A successful call
+// returns err == nil, not err == io.EOF. Because ReadAll is defined to read
+// until EOF, it does not treat end of file as an error to be reported.
+func (kr *KVReader) ReadAll() ([]KeyValue, error) {
+	var pairs []KeyValue
+	for {
+		kv, err := kr.Read()
+		if err == io.EOF {
+			return pairs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, kv)
+	}
+}
+
+// Read reads one key/value pair from r. If a key appears more than once in the
+// same section, Read returns a ParseError wrapping ErrDuplicateKey at the
+// position of the key. It returns the same errors as [Reader.Read] and a
+// ParseError wrapping ErrNoSeparator for a line without a Separator character.
+// If there is no data left to be read, Read returns io.EOF.
+func (kr *KVReader) Read() (KeyValue, error) {
+	rec, err := kr.ReadRecord()
+	return KeyValue{rec.Key, rec.Value}, err
+}
+
+// ReadRecord reads one key/value pair from r along with its inline comment and
+// any block comment directly above it. It returns the same errors as
+// [KVReader.Read].
+func (kr *KVReader) ReadRecord() (KVRecord, error) {
+	if !kr.verifyKV() {
+		return KVRecord{}, ErrInvalidParams
+	}
+	kr.r.Parameters, kr.r.FileName = kr.Parameters, kr.FileName
+
+	rec, err := kr.r.readRecord()
+	if err != nil {
+		return KVRecord{}, err
+	}
+
+	key, pos := kr.r.key, kr.r.keyPos
+	id := [2]string{kr.r.section, key}
+	if first, exists := kr.seen[id]; exists {
+		return KVRecord{}, kr.r.newParseError(pos.Line, pos, fmt.Errorf(
+			"%w %q (first on line %d)", ErrDuplicateKey, key, first.Line))
+	}
+	kr.seen[id] = pos
+
+	return KVRecord{key, rec}, nil
+}
+
+// Section returns the name of the section of the most recently read pair. It is
+// only set if Sections is enabled in the Parameters.
+func (kr *KVReader) Section() string {
+	return kr.r.section
+}
+
+// KeyPos returns the line and column of the start of the key of the most
+// recently read pair. For quoted keys, this is the position of the opening Raw
+// character.
+//
+// If KeyPos is called before any pair has been read, it returns 0, 0.
+func (kr *KVReader) KeyPos() (line, column int) {
+	return kr.r.keyPos.Line, kr.r.keyPos.Column
+}
+
+// isKeyLine determines if the line contains a key, which is true for every line
+// that is not blank or a comment line.
+func (p Parameters) isKeyLine(line string) bool {
+	c, size := utf8.DecodeRuneInString(
+		strings.TrimLeftFunc(line, unicode.IsSpace))
+	return size > 0 && c != p.Comment
+}
+
+// readKey reads the key and Separator character at the start of the line, which
+// starts at pos. It returns the rest of the line, with its leading whitespace
+// trimmed, and its position.
+func (r *Reader) readKey(line string, pos Position) (string, Position, error) {
+	key, n, errAt, err := r.cutKey(line)
+	if err != nil {
+		return "", pos, r.newParseError(pos.Line, pos.add(errAt), err)
+	}
+	r.key, r.keyPos = key, pos
+
+	rest := strings.TrimLeftFunc(line[n:], unicode.IsSpace)
+	return rest, pos.add(len(line) - len(rest)), nil
+}
+
+// cutKey decodes the key at the start of the line and returns it along with the
+// index after the Separator character that follows it. On a syntax error, it
+// returns the index in the line where the error occurred.
+func (p Parameters) cutKey(line string) (key string, n, errAt int, err error) {
+	sep := p.separator()
+	quoted := false
+	i := 0
+	if c, size := utf8.DecodeRuneInString(line); c == p.Raw {
+		quoted = true
+		i = size
+	}
+
+	var b strings.Builder
+	for i < len(line) {
+		c, size := utf8.DecodeRuneInString(line[i:])
+		if c == p.Escape {
+			next, m := utf8.DecodeRuneInString(line[i+size:])
+			if m > 0 && (next == p.Escape || next == p.Comment ||
+				next == p.Raw || next == sep ||
+				(p.Sections && next == sectionOpen)) {
+				b.WriteRune(next)
+				i += size + m
+				continue
+			} else if quoted && p.EscapeSequences {
+				if decoded, m, ok := p.decodeSequence(line[i+size:]); ok {
+					b.WriteString(decoded)
+					i += size + m
+					continue
+				}
+			}
+			if p.Strict {
+				return "", 0, i, ErrInvalidEscape
+			}
+		} else if quoted && c == p.Raw {
+			// A quoted key can only be followed by whitespace and the
+			// Separator character
+			i += size
+			rest := strings.TrimLeftFunc(line[i:], unicode.IsSpace)
+			i += len(line[i:]) - len(rest)
+			c, m := utf8.DecodeRuneInString(rest)
+			if c != sep {
+				return "", 0, i, ErrNoSeparator
+			}
+			return b.String(), i + m, 0, nil
+		} else if !quoted && c == sep {
+			key = strings.TrimRightFunc(b.String(), unicode.IsSpace)
+			return key, i + size, 0, nil
+		} else if !quoted && c == p.Comment {
+			return "", 0, i, ErrNoSeparator
+		}
+
+		b.WriteString(line[i : i+size])
+		i += size
+	}
+
+	// Point at the end of the line, before the line ending
+	end := len(strings.TrimRight(line, "\r\n"))
+	if quoted {
+		return "", 0, end, ErrNoClosingRaw
+	}
+	return "", 0, end, ErrNoSeparator
+}
+
+// KVWriter writes key/value pairs using LSV-KV encoding, as described by
+// [KVReader].
+//
+// The exported fields can be changed to customize the details before the first
+// call to [KVWriter.Write] or [KVWriter.WriteAll]. They have the same meaning
+// as those of [Writer].
+//
+// The writes of individual pairs are buffered. After all data has been written,
+// the user should call the [KVWriter.Flush] method to guarantee all data has
+// been forwarded to the underlying [io.Writer]. Any errors that occurred should
+// be checked by calling the [KVWriter.Error] method.
+type KVWriter struct {
+	Parameters
+
+	// LeadingCommentSpace is the space written before the Comment character
+	// when writing a comment.
+	LeadingCommentSpace string
+
+	// TrailingCommentSpace is the space written after the Comment character
+	// when writing a comment.
+	TrailingCommentSpace string
+
+	// SeparatorSpace is the space written before and after the Separator
+	// character.
+	SeparatorSpace string
+
+	// UseCRLF uses \r\n as the line terminator if set to true.
+	UseCRLF bool
+
+	// AlignComments aligns the inline comments of consecutive pairs as
+	// described by Writer.AlignComments.
+	AlignComments bool
+
+	w *Writer
+}
+
+// NewKVWriter returns a new KVWriter that writes to w.
+func NewKVWriter(w io.Writer) *KVWriter {
+	return NewCustomKVWriter(w, DefaultParameters())
+}
+
+// NewCustomKVWriter returns a new KVWriter that writes to w with custom LSV
+// parameters.
+func NewCustomKVWriter(w io.Writer, p Parameters) *KVWriter {
+	return &KVWriter{
+		Parameters:           p,
+		LeadingCommentSpace:  defaultLeadingCommentSpace,
+		TrailingCommentSpace: defaultTrailingCommentSpace,
+		SeparatorSpace:       " ",
+		w:                    NewCustomWriter(w, p),
+	}
+}
+
+// WriteAll writes multiple key/value pairs to w using [KVWriter.Write] and then
+// calls [KVWriter.Flush], returning any error from the [KVWriter.Flush].
+func (kw *KVWriter) WriteAll(pairs []KeyValue) error {
+	if err := kw.sync(); err != nil {
+		return err
+	}
+
+	for _, kv := range pairs {
+		if err := kw.writePair(kv.Key, kv.Value, "", false); err != nil {
+			return err
+		}
+	}
+	return kw.w.flush()
+}
+
+// Write writes a single key/value pair to w along with any necessary quoting
+// and escaping. Any pair written successfully is read back unchanged by a
+// [KVReader] with the same Parameters. If the pair cannot be encoded that way,
+// Write returns ErrUnrepresentable and nothing is written.
+//
+// Writes are buffered, so [KVWriter.Flush] must eventually be called to ensure
+// that the pair is written to the underlying [io.Writer].
+func (kw *KVWriter) Write(key, value string) error {
+	return kw.WriteComment(key, value, "")
+}
+
+// WriteComment writes a single key/value pair to w like [KVWriter.Write]
+// followed by the inline comment, if one is specified. The comment cannot
+// contain newlines.
+func (kw *KVWriter) WriteComment(key, value, comment string) error {
+	if err := kw.sync(); err != nil {
+		return err
+	}
+	return kw.writePair(key, value, comment, false)
+}
+
+// WriteRecord writes a single record to w. Each line of the block comment is
+// written on its own line above the pair and the inline comment is appended to
+// the end of the value. If Quoted is true, then the value is written as a raw
+// string literal even if it does not need to be.
+func (kw *KVWriter) WriteRecord(rec KVRecord) error {
+	if err := kw.sync(); err != nil {
+		return err
+	}
+
+	for _, line := range rec.BlockComment {
+		if err := kw.w.writeBlockComment(line); err != nil {
+			return err
+		}
+	}
+	return kw.writePair(rec.Key, rec.Value, rec.Comment, rec.Quoted)
+}
+
+// WriteBlockComment writes the text as a block comment as described by
+// [Writer.WriteBlockComment].
+func (kw *KVWriter) WriteBlockComment(text string) error {
+	if err := kw.sync(); err != nil {
+		return err
+	}
+	return kw.w.writeBlockComment(text)
+}
+
+// WriteBlankLine writes an empty line.
+func (kw *KVWriter) WriteBlankLine() error {
+	if err := kw.sync(); err != nil {
+		return err
+	}
+	return kw.w.WriteBlankLine()
+}
+
+// Flush writes any buffered data to the underlying [io.Writer]. To check if an
+// error occurred during the Flush, call [KVWriter.Error].
+func (kw *KVWriter) Flush() {
+	kw.w.Flush()
+}
+
+// Error reports any error that has occurred during a previous Write or Flush.
+func (kw *KVWriter) Error() error {
+	return kw.w.Error()
+}
+
+// sync copies the exported fields to the underlying Writer and verifies the
+// Parameters.
+func (kw *KVWriter) sync() error {
+	if !kw.verifyKV() {
+		return ErrInvalidParams
+	}
+	kw.w.Parameters = kw.Parameters
+	kw.w.LeadingCommentSpace = kw.LeadingCommentSpace
+	kw.w.TrailingCommentSpace = kw.TrailingCommentSpace
+	kw.w.UseCRLF = kw.UseCRLF
+	kw.w.AlignComments = kw.AlignComments
+	return nil
+}
+
+// writePair writes the key, Separator character, and value of a pair followed
+// by its inline comment. If quote is true, the value is always written as a raw
+// string literal.
+func (kw *KVWriter) writePair(key, value, comment string, quote bool) error {
+	if strings.IndexByte(comment, '\n') > -1 {
+		return ErrCommentNewline
+	}
+
+	encodedKey, err := kw.w.encodeKey(key)
+	if err != nil {
+		return err
+	}
+
+	// An empty value is written as nothing after the Separator character
+	encoded := encodedKey + kw.SeparatorSpace + string(kw.separator())
+	if value != "" || quote {
+		encodedValue, err := kw.w.encodeValue(value, quote)
+		if err != nil {
+			return err
+		}
+		encoded += kw.SeparatorSpace + encodedValue
+	}
+
+	return kw.w.writeEncoded(encoded, comment)
+}
+
+// encodeKey returns the key as it is written to the LSV-KV, with any necessary
+// quoting and escaping. It returns ErrUnrepresentable if the key contains a
+// newline and EscapeSequences is not set.
+func (w *Writer) encodeKey(key string) (string, error) {
+	esc, sep := string(w.Escape), string(w.separator())
+	if key != "" && !unicode.IsSpace(firstRune(key)) &&
+		!unicode.IsSpace(lastRune(key)) && firstRune(key) != w.Raw &&
+		!strings.ContainsRune(key, '\n') &&
+		!(w.EscapeSequences && needsSequence(key)) {
+		comment := string(w.Comment)
+		return w.escapeSection(strings.NewReplacer(esc, esc+esc,
+			comment, esc+comment, sep, esc+sep).Replace(key)), nil
+	}
+
+	// A quoted key must be on a single line
+	raw := string(w.Raw)
+	if w.EscapeSequences {
+		encoded := strings.ReplaceAll(w.encodeSequences(key), "\n", esc+"n")
+		return raw + encoded + raw, nil
+	} else if strings.ContainsRune(key, '\n') {
+		return "", ErrUnrepresentable
+	}
+	return raw + strings.NewReplacer(
+		esc, esc+esc, raw, esc+raw).Replace(key) + raw, nil
+}
@@ -0,0 +1,327 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var kvReadTests = []struct {
+	Name   string
+	Input  string
+	Output []KeyValue
+}{
+	{"Empty", "", nil},
+	{"Simple", "a = 1\nb=2\n  c  =  3  \n",
+		[]KeyValue{{"a", "1"}, {"b", "2"}, {"c", "3"}}},
+	{"Comments", "# Block\na = 1 # inline\n\n# only\n",
+		[]KeyValue{{"a", "1"}}},
+	{"EmptyValue", "a =\nb = # c\nc = \"\"\n",
+		[]KeyValue{{"a", ""}, {"b", ""}, {"c", ""}}},
+	{"EmptyKey", "= a\n", []KeyValue{{"", "a"}}},
+	{"QuotedEmptyKey", "\"\" = b\n", []KeyValue{{"", "b"}}},
+	{"SeparatorInValue", "a = b = c\n", []KeyValue{{"a", "b = c"}}},
+	{"EscapedKey", "a\\=b\\#c\\\\ = d\n", []KeyValue{{"a=b#c\\", "d"}}},
+	{"QuotedKey", "\" a = b # \\\"c\\\" \" = d\n",
+		[]KeyValue{{" a = b # \"c\" ", "d"}}},
+	{"QuotedValue", "a = \"  b # c\n d\"  # e\nf = g\n",
+		[]KeyValue{{"a", "  b # c\n d"}, {"f", "g"}}},
+	{"CRLF", "a = 1\r\nb = \"2\r\n3\"\r\n",
+		[]KeyValue{{"a", "1"}, {"b", "2\r\n3"}}},
+}
+
+// Tests that KVReader.ReadAll returns the expected pairs in both the lenient
+// and strict grammar.
+func TestKVReader_ReadAll(t *testing.T) {
+	for _, p := range []Parameters{DefaultParameters(), strictParameters()} {
+		for _, tt := range kvReadTests {
+			t.Run(tt.Name, func(t *testing.T) {
+				pairs, err := NewCustomKVReader(
+					strings.NewReader(tt.Input), p).ReadAll()
+				if err != nil {
+					t.Fatalf("Failed to read (strict %t): %+v", p.Strict, err)
+				}
+				if !reflect.DeepEqual(tt.Output, pairs) {
+					t.Errorf("Unexpected pairs (strict %t)."+
+						"\nexpected: %q\nreceived: %q", p.Strict, tt.Output,
+						pairs)
+				}
+			})
+		}
+	}
+}
+
+// Tests that KVReader.ReadAll returns a ParseError at the expected position.
+func TestKVReader_ReadAll_ParseError(t *testing.T) {
+	type test struct {
+		Name   string
+		Input  string
+		Strict bool
+		Error  ParseError
+	}
+
+	tests := []test{
+		{"NoSeparator", "a = 1\n  b\n", false,
+			ParseError{StartLine: 2, Line: 2, Column: 4, Offset: 9,
+				Err: ErrNoSeparator}},
+		{"CommentBeforeSeparator", "a # = 1\n", false,
+			ParseError{StartLine: 1, Line: 1, Column: 3, Offset: 2,
+				Err: ErrNoSeparator}},
+		{"TextAfterQuotedKey", "\"a\" b = 1\n", false,
+			ParseError{StartLine: 1, Line: 1, Column: 5, Offset: 4,
+				Err: ErrNoSeparator}},
+		{"QuotedKeyNotClosed", "\"a = 1\r\n", false,
+			ParseError{StartLine: 1, Line: 1, Column: 7, Offset: 6,
+				Err: ErrNoClosingRaw}},
+		{"ValueNotClosed", "a = \"1\n", false,
+			ParseError{StartLine: 1, Line: 2, Column: 1, Offset: 7,
+				Err: ErrNoClosingRaw}},
+		{"StrictInvalidEscape", "a\\b = 1\n", true,
+			ParseError{StartLine: 1, Line: 1, Column: 2, Offset: 1,
+				Err: ErrInvalidEscape}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			p := DefaultParameters()
+			p.Strict = tt.Strict
+			_, err := NewCustomKVReader(
+				strings.NewReader(tt.Input), p).ReadAll()
+			var pe *ParseError
+			if !errors.As(err, &pe) || !reflect.DeepEqual(&tt.Error, pe) {
+				t.Errorf("Unexpected error.\nexpected: %+v\nreceived: %+v",
+					&tt.Error, err)
+			}
+		})
+	}
+}
+
+// Tests that KVReader.Read returns ErrDuplicateKey at the position of a
+// repeated key and can continue reading after it.
+func TestKVReader_Read_DuplicateKey(t *testing.T) {
+	r := NewKVReader(strings.NewReader("a = 1\nb = 2\n \"a\" = 3\nc = 4\n"))
+	r.FileName = "dup.lsv"
+	for i := 0; i < 2; i++ {
+		if _, err := r.Read(); err != nil {
+			t.Fatalf("Failed to read pair %d: %+v", i, err)
+		}
+	}
+
+	_, err := r.Read()
+	expected := `dup.lsv: parse error on line 3, column 2: duplicate key "a" ` +
+		`(first on line 1)`
+	if !errors.Is(err, ErrDuplicateKey) || err.Error() != expected {
+		t.Errorf("Unexpected error.\nexpected: %s\nreceived: %v",
+			expected, err)
+	}
+
+	kv, err := r.Read()
+	if err != nil || kv != (KeyValue{"c", "4"}) {
+		t.Errorf("Unexpected pair after error: %q, %+v", kv, err)
+	}
+	if line, column := r.KeyPos(); line != 4 || column != 1 {
+		t.Errorf("Unexpected key position: %d:%d", line, column)
+	}
+}
+
+// Tests that KVReader.ReadRecord returns the comments and quoting of each pair.
+func TestKVReader_ReadRecord(t *testing.T) {
+	src := "# Block\na = \"1\" # Inline\n\n# Detached\n\nb = 2\n"
+	expected := []KVRecord{
+		{"a", Record{ValueComment{"1", "Inline"}, true, []string{"Block"}}},
+		{"b", Record{ValueComment{"2", ""}, false, nil}},
+	}
+
+	r := NewKVReader(strings.NewReader(src))
+	var records []KVRecord
+	for {
+		rec, err := r.ReadRecord()
+		if err != nil {
+			break
+		}
+		records = append(records, rec)
+	}
+
+	if !reflect.DeepEqual(expected, records) {
+		t.Errorf("Unexpected records.\nexpected: %+v\nreceived: %+v",
+			expected, records)
+	}
+}
+
+// Tests that a custom Separator is used and that invalid Separators are
+// rejected.
+func TestKVReader_Separator(t *testing.T) {
+	p := DefaultParameters()
+	p.Separator = ':'
+	pairs, err := NewCustomKVReader(
+		strings.NewReader("a: b = c\nd\\:e: f\n"), p).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read: %+v", err)
+	}
+	expected := []KeyValue{{"a", "b = c"}, {"d:e", "f"}}
+	if !reflect.DeepEqual(expected, pairs) {
+		t.Errorf("Unexpected pairs.\nexpected: %q\nreceived: %q",
+			expected, pairs)
+	}
+
+	for _, sep := range []rune{'#', '"', '\\', ' '} {
+		p.Separator = sep
+		_, err = NewCustomKVReader(strings.NewReader(""), p).ReadAll()
+		if !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("Unexpected error for %q.\nexpected: %v\nreceived: %v",
+				sep, ErrInvalidParams, err)
+		}
+	}
+
+	// The default Separator cannot be used if it is one of the other
+	// characters
+	p = Parameters{Comment: '=', Raw: '"', Escape: '\\'}
+	if err = NewKVWriter(&bytes.Buffer{}).Write("a", "b"); err != nil {
+		t.Errorf("Unexpected error: %+v", err)
+	}
+	if err = NewCustomKVWriter(&bytes.Buffer{}, p).Write("a", "b"); !errors.Is(
+		err, ErrInvalidParams) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrInvalidParams, err)
+	}
+}
+
+// Tests that the KVWriter writes pairs as expected.
+func TestKVWriter_Write(t *testing.T) {
+	type test struct {
+		Key, Value, Comment string
+		Expected            string
+	}
+
+	tests := []test{
+		{"a", "1", "", "a = 1\n"},
+		{"a", "", "", "a =\n"},
+		{"a", "", "c", "a =\t# c\n"},
+		{"", "b", "", "\"\" = b\n"},
+		{"a=b#c\\", "d#e", "", "a\\=b\\#c\\\\ = d\\#e\n"},
+		{" a\"", " b", "", "\" a\\\"\" = \" b\"\n"},
+		{"\"a", "b\nc", "d", "\"\\\"a\" = \"b\nc\"\t# d\n"},
+	}
+
+	for _, tt := range tests {
+		var buff bytes.Buffer
+		w := NewKVWriter(&buff)
+		if err := w.WriteComment(tt.Key, tt.Value, tt.Comment); err != nil {
+			t.Errorf("Failed to write %q: %+v", tt.Key, err)
+		}
+		w.Flush()
+
+		if buff.String() != tt.Expected {
+			t.Errorf("Unexpected output for %q.\nexpected: %q\nreceived: %q",
+				tt.Key, tt.Expected, buff.String())
+		}
+	}
+}
+
+// Tests that the KVWriter returns the expected errors.
+func TestKVWriter_Write_Error(t *testing.T) {
+	w := NewKVWriter(&bytes.Buffer{})
+	if err := w.Write("a\nb", "c"); !errors.Is(err, ErrUnrepresentable) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrUnrepresentable, err)
+	}
+	if err := w.WriteComment("a", "b", "c\nd"); !errors.Is(
+		err, ErrCommentNewline) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrCommentNewline, err)
+	}
+}
+
+// Tests that records written by KVWriter.WriteRecord with aligned comments are
+// read back unchanged.
+func TestKVWriter_WriteRecord(t *testing.T) {
+	records := []KVRecord{
+		{"name", Record{ValueComment{"lsv", "Name"}, false, []string{"Info"}}},
+		{"version", Record{ValueComment{"1", "Version"}, true, nil}},
+	}
+
+	var buff bytes.Buffer
+	w := NewKVWriter(&buff)
+	w.AlignComments = true
+	w.LeadingCommentSpace = " "
+	for _, rec := range records {
+		if err := w.WriteRecord(rec); err != nil {
+			t.Fatalf("Failed to write %q: %+v", rec.Key, err)
+		}
+	}
+	w.Flush()
+
+	expected := "# Info\nname = lsv    # Name\nversion = \"1\" # Version\n"
+	if buff.String() != expected {
+		t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+			expected, buff.String())
+	}
+
+	r := NewKVReader(&buff)
+	for _, rec := range records {
+		read, err := r.ReadRecord()
+		if err != nil {
+			t.Fatalf("Failed to read %q: %+v", rec.Key, err)
+		}
+		if !reflect.DeepEqual(rec, read) {
+			t.Errorf("Unexpected record.\nexpected: %+v\nreceived: %+v",
+				rec, read)
+		}
+	}
+}
+
+// Tests that every combination of special characters in keys and values
+// written by a KVWriter is read back unchanged by a KVReader.
+func TestKVWriter_RoundTrip(t *testing.T) {
+	sequences := sequenceParameters(true)
+	sequences.Separator = ':'
+	params := []Parameters{DefaultParameters(), strictParameters(), sequences,
+		{Comment: ';', Raw: '\'', Escape: '/', Separator: '|'}}
+	for _, p := range params {
+		alphabet := []string{"a", " ", "\r", string(p.Comment), string(p.Raw),
+			string(p.Escape), string(p.separator())}
+		if p.EscapeSequences {
+			alphabet = append(alphabet, "\n", "\x00")
+		}
+		keys := []string{""}
+		for n := 1; n <= 3; n++ {
+			keys = append(keys, combinations(alphabet, n)...)
+		}
+
+		// Some values cannot be written in the lenient grammar
+		var buff bytes.Buffer
+		var pairs []KeyValue
+		w := NewCustomKVWriter(&buff, p)
+		for i, key := range keys {
+			kv := KeyValue{key, keys[len(keys)-1-i]}
+			err := w.WriteComment(kv.Key, kv.Value, "c")
+			if errors.Is(err, ErrUnrepresentable) && !p.Strict {
+				continue
+			} else if err != nil {
+				t.Fatalf("Failed to write %q: %+v", kv, err)
+			}
+			pairs = append(pairs, kv)
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			t.Fatalf("Failed to flush: %+v", err)
+		}
+
+		read, err := NewCustomKVReader(&buff, p).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to read for %+v: %+v", p, err)
+		}
+		if !reflect.DeepEqual(pairs, read) {
+			t.Errorf("KVReader did not read back pairs for %+v.", p)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Lint rules. Each Diagnostic reports the rule that found it so that rules can
+// be suppressed individually.
+const (
+	// RuleParseError reports a source that cannot be parsed. Linting stops at
+	// the first parse error.
+	RuleParseError = "parse-error"
+
+	// RuleDuplicate reports a value that appears earlier in the source.
+	RuleDuplicate = "duplicate"
+
+	// RuleUnnecessaryQuote reports a raw string literal that would be read as
+	// the same value without quotes.
+	RuleUnnecessaryQuote = "unnecessary-quote"
+
+	// RuleURLFragment reports an unescaped Comment character directly after a
+	// value that looks like a URL, such as the "#" of a fragment, which starts
+	// a comment instead of being part of the value.
+	RuleURLFragment = "url-fragment"
+
+	// RuleTrailingEscape reports an unquoted value that ends in the Escape
+	// character, which escapes nothing. It is not used in strict mode.
+	RuleTrailingEscape = "trailing-escape"
+
+	// RuleMixedLineEndings reports a line ending that differs from the line
+	// ending of the first line.
+	RuleMixedLineEndings = "mixed-line-endings"
+
+	// RuleStrayRaw reports an unescaped Raw character inside a raw string
+	// literal.
+	RuleStrayRaw = "stray-raw"
+
+	// RuleWhitespaceValue reports a value that contains only whitespace.
+	RuleWhitespaceValue = "whitespace-value"
+)
+
+// Diagnostic is a suspicious or ambiguous construct found by Lint.
+type Diagnostic struct {
+	Pos     Position // Position of the construct in the source
+	Rule    string   // ID of the rule that found the construct
+	Message string   // Description of the problem
+}
+
+// String returns the diagnostic in the form "line:column: message (rule)".
+func (d Diagnostic) String() string {
+	return d.Pos.String() + ": " + d.Message + " (" + d.Rule + ")"
+}
+
+// Lint reads all of r using the Parameters and returns diagnostics for
+// constructs that are valid but likely to be mistakes, sorted by position. If
+// the source cannot be read, a Diagnostic with RuleParseError is returned for
+// the error and linting stops.
+func Lint(r io.Reader, p Parameters) []Diagnostic {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return []Diagnostic{{Rule: RuleParseError, Message: err.Error()}}
+	}
+
+	l := &linter{Parameters: p, src: src, seen: make(map[string]Position)}
+	l.lintLineEndings()
+	l.lintValues()
+
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		return l.diags[i].Pos.Offset < l.diags[j].Pos.Offset
+	})
+	return l.diags
+}
+
+// linter holds the state of a single call to Lint.
+type linter struct {
+	Parameters
+	src        []byte
+	lineStarts []int
+	seen       map[string]Position
+	diags      []Diagnostic
+}
+
+// report adds a Diagnostic.
+func (l *linter) report(pos Position, rule, format string, a ...interface{}) {
+	l.diags = append(l.diags, Diagnostic{pos, rule, fmt.Sprintf(format, a...)})
+}
+
+// position returns the Position of the byte offset in the source.
+func (l *linter) position(offset int) Position {
+	line := sort.SearchInts(l.lineStarts, offset+1)
+	return Position{
+		Line:   line,
+		Column: offset - l.lineStarts[line-1] + 1,
+		Offset: int64(offset),
+	}
+}
+
+// lintLineEndings records the start of every line and reports the first line
+// ending that differs from the first one.
+func (l *linter) lintLineEndings() {
+	l.lineStarts = []int{0}
+	var first string
+	var reported bool
+	for i, c := range l.src {
+		if c != '\n' {
+			continue
+		}
+		l.lineStarts = append(l.lineStarts, i+1)
+
+		ending, end := "\n", i
+		if i > 0 && l.src[i-1] == '\r' {
+			ending, end = "\r\n", i-1
+		}
+		if first == "" {
+			first = ending
+		} else if ending != first && !reported {
+			l.report(l.position(end), RuleMixedLineEndings,
+				"line ends in %q but the first line ends in %q", ending, first)
+			reported = true
+		}
+	}
+}
+
+// lintValues reads each value and reports any problems with it.
+func (l *linter) lintValues() {
+	r := NewCustomReader(bytes.NewReader(l.src), l.Parameters)
+	for {
+		rec, err := r.ReadRecord()
+		if err == io.EOF {
+			return
+		} else if err != nil {
+			var pe *ParseError
+			if errors.As(err, &pe) {
+				pos := Position{pe.Line, pe.Column, pe.Offset}
+				l.report(pos, RuleParseError, "%v", pe.Err)
+			} else {
+				l.report(Position{}, RuleParseError, "%v", err)
+			}
+			return
+		}
+
+		start, end := r.ValueRange()
+		l.lintValue(rec, start, end)
+	}
+}
+
+// lintValue reports any problems with the value read from the source between
+// start and end.
+func (l *linter) lintValue(rec Record, start, end Position) {
+	text := string(l.src[start.Offset:end.Offset])
+
+	if first, exists := l.seen[rec.Value]; exists {
+		l.report(start, RuleDuplicate,
+			"duplicate of value on line %d", first.Line)
+	} else {
+		l.seen[rec.Value] = start
+	}
+
+	if rec.Value != "" && strings.TrimSpace(rec.Value) == "" {
+		l.report(start, RuleWhitespaceValue, "value contains only whitespace")
+	}
+
+	if rec.Quoted {
+		l.lintQuoted(rec, start, text)
+		return
+	}
+
+	// In strict mode, a trailing Escape character is always part of an escape
+	// sequence
+	if !l.Strict && lastRune(text) == l.Escape {
+		l.report(l.position(int(end.Offset)-utf8.RuneLen(l.Escape)),
+			RuleTrailingEscape, "value ends in escape character %q", l.Escape)
+	}
+
+	if c, _ := utf8.DecodeRune(l.src[end.Offset:]); c == l.Comment &&
+		strings.Contains(rec.Value, "://") {
+		l.report(end, RuleURLFragment,
+			"%q starts a comment; escape it to include it in the URL",
+			l.Comment)
+	}
+}
+
+// lintQuoted reports problems specific to the raw string literal in text.
+func (l *linter) lintQuoted(rec Record, start Position, text string) {
+	w := Writer{Parameters: l.Parameters}
+	if encoded, err := w.encodeValue(rec.Value, false); err == nil &&
+		rec.Value != "" && encoded == rec.Value {
+		l.report(start, RuleUnnecessaryQuote, "value does not need quotes")
+	}
+
+	// Look for unescaped Raw characters between the opening and closing ones
+	size := utf8.RuneLen(l.Raw)
+	inner := text[size : len(text)-size]
+	for i, c := range inner {
+		if c == l.Raw && !l.escapedAt(inner, i) {
+			l.report(l.position(int(start.Offset)+size+i), RuleStrayRaw,
+				"unescaped %q inside quoted value", l.Raw)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests that Lint reports the expected diagnostics for each rule.
+func TestLint(t *testing.T) {
+	type test struct {
+		Name     string
+		Input    string
+		Expected []Diagnostic
+	}
+
+	tests := []test{{
+		Name:     "Clean",
+		Input:    "# Comment\na\n\" b\"\n\"c # d\" # e\nhttps://x.com/\\#f\n",
+		Expected: nil,
+	}, {
+		Name:  "Duplicate",
+		Input: "a\nb\n  a # Again\n\"a\"\n",
+		Expected: []Diagnostic{
+			{Position{3, 3, 6}, RuleDuplicate,
+				"duplicate of value on line 1"},
+			{Position{4, 1, 16}, RuleDuplicate,
+				"duplicate of value on line 1"},
+			{Position{4, 1, 16}, RuleUnnecessaryQuote,
+				"value does not need quotes"},
+		},
+	}, {
+		Name:  "UnnecessaryQuote",
+		Input: "\"a b\"\n\"\"\n\"a#\"\n\" c\"\n",
+		Expected: []Diagnostic{
+			{Position{1, 1, 0}, RuleUnnecessaryQuote,
+				"value does not need quotes"},
+		},
+	}, {
+		Name:  "URLFragment",
+		Input: "http://a.com/page#top\nb#c\nhttp://a.com/ # Comment\n",
+		Expected: []Diagnostic{
+			{Position{1, 18, 17}, RuleURLFragment,
+				"'#' starts a comment; escape it to include it in the URL"},
+		},
+	}, {
+		Name:  "TrailingEscape",
+		Input: "a\\\nb\\  # Comment\n\"c\\ \" \n",
+		Expected: []Diagnostic{
+			{Position{1, 2, 1}, RuleTrailingEscape,
+				"value ends in escape character '\\\\'"},
+			{Position{2, 2, 4}, RuleTrailingEscape,
+				"value ends in escape character '\\\\'"},
+		},
+	}, {
+		Name:  "MixedLineEndings",
+		Input: "a\r\nb\r\nc\nd\n",
+		Expected: []Diagnostic{
+			{Position{3, 2, 7}, RuleMixedLineEndings,
+				"line ends in \"\\n\" but the first line ends in \"\\r\\n\""},
+		},
+	}, {
+		Name:  "StrayRaw",
+		Input: "a\n\" b\"c\"\n\" d\\\"e\"\n\"f\n\"g\"\n",
+		Expected: []Diagnostic{
+			{Position{2, 4, 5}, RuleStrayRaw,
+				"unescaped '\"' inside quoted value"},
+			{Position{5, 1, 20}, RuleStrayRaw,
+				"unescaped '\"' inside quoted value"},
+		},
+	}, {
+		Name:  "WhitespaceValue",
+		Input: "\" \"\n\"\t\t\"\n\"\"\n",
+		Expected: []Diagnostic{
+			{Position{1, 1, 0}, RuleWhitespaceValue,
+				"value contains only whitespace"},
+			{Position{2, 1, 4}, RuleWhitespaceValue,
+				"value contains only whitespace"},
+		},
+	}, {
+		Name:  "ParseError",
+		Input: "a\na\n\"b\n",
+		Expected: []Diagnostic{
+			{Position{2, 1, 2}, RuleDuplicate,
+				"duplicate of value on line 1"},
+			{Position{4, 1, 7}, RuleParseError, ErrNoClosingRaw.Error()},
+		},
+	},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			diags := Lint(strings.NewReader(tt.Input), DefaultParameters())
+			if !reflect.DeepEqual(tt.Expected, diags) {
+				t.Errorf("Unexpected diagnostics."+
+					"\nexpected: %v\nreceived: %v", tt.Expected, diags)
+			}
+		})
+	}
+}
+
+// Tests that Lint uses the custom Parameters.
+func TestLint_CustomParameters(t *testing.T) {
+	p := Parameters{Comment: ';', Raw: '\'', Escape: '/'}
+	src := "'a'\n' b'c'\nhttp://d.com/e;f\nhttp://d.com/e#f/\n"
+	expected := []Diagnostic{
+		{Position{1, 1, 0}, RuleUnnecessaryQuote,
+			"value does not need quotes"},
+		{Position{2, 4, 7}, RuleStrayRaw,
+			"unescaped '\\'' inside quoted value"},
+		{Position{3, 15, 25}, RuleURLFragment,
+			"';' starts a comment; escape it to include it in the URL"},
+		{Position{4, 17, 44}, RuleTrailingEscape,
+			"value ends in escape character '/'"},
+	}
+
+	diags := Lint(strings.NewReader(src), p)
+	if !reflect.DeepEqual(expected, diags) {
+		t.Errorf("Unexpected diagnostics.\nexpected: %v\nreceived: %v",
+			expected, diags)
+	}
+}
+
+// Tests that Lint in strict mode does not report escaped Escape characters and
+// reports invalid escapes as parse errors.
+func TestLint_Strict(t *testing.T) {
+	p := DefaultParameters()
+	p.Strict = true
+	src := "a\\\\\nb\\c\n"
+	expected := []Diagnostic{
+		{Position{2, 2, 5}, RuleParseError, ErrInvalidEscape.Error()},
+	}
+
+	diags := Lint(strings.NewReader(src), p)
+	if !reflect.DeepEqual(expected, diags) {
+		t.Errorf("Unexpected diagnostics.\nexpected: %v\nreceived: %v",
+			expected, diags)
+	}
+}
+
+// Tests that Lint reports invalid Parameters as a parse error.
+func TestLint_InvalidParams(t *testing.T) {
+	expected := []Diagnostic{{Rule: RuleParseError,
+		Message: ErrInvalidParams.Error()}}
+	diags := Lint(strings.NewReader("a\n"), Parameters{})
+	if !reflect.DeepEqual(expected, diags) {
+		t.Errorf("Unexpected diagnostics.\nexpected: %v\nreceived: %v",
+			expected, diags)
+	}
+}
+
+// Tests that Diagnostic.String returns the expected string.
+func TestDiagnostic_String(t *testing.T) {
+	d := Diagnostic{Position{3, 5, 20}, RuleDuplicate, "message"}
+	expected := "3:5: message (duplicate)"
+	if d.String() != expected {
+		t.Errorf("Unexpected string.\nexpected: %q\nreceived: %q",
+			expected, d.String())
+	}
+}
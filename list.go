@@ -0,0 +1,191 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+)
+
+// List is a list of values of type T that is read from and written to an LSV.
+// Each value is converted using the Parse and Format functions. List implements
+// [encoding.TextMarshaler] and [encoding.TextUnmarshaler], so it can be used
+// directly as a field of a configuration struct, and [io.WriterTo] and
+// [io.ReaderFrom].
+//
+// The zero value is ready to use. If Parse or Format is nil, values are
+// converted in the same way as [Reader.Decode] and [Writer.Encode], and using
+// a type T they do not support returns ErrDecodeTarget or ErrEncodeSource. If
+// Parameters is the zero value, the default Parameters are used.
+type List[T any] struct {
+	// Values are the values in the list.
+	Values []T
+
+	// Parse converts a value read from the LSV to T. If it returns an error,
+	// it is wrapped in a DecodeError with the line of the value.
+	Parse func(string) (T, error)
+
+	// Format converts a value to the text written to the LSV.
+	Format func(T) string
+
+	// Parameters are the Parameters used to read and write the LSV.
+	Parameters Parameters
+}
+
+// MarshalText returns the LSV encoding of the values.
+func (l List[T]) MarshalText() ([]byte, error) {
+	var buff bytes.Buffer
+	if _, err := l.WriteTo(&buff); err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+// UnmarshalText replaces the values with those read from the LSV-encoded text.
+// On error, the values are not modified.
+func (l *List[T]) UnmarshalText(text []byte) error {
+	values, _, err := l.read(bytes.NewReader(text))
+	if err != nil {
+		return err
+	}
+	l.Values = values
+	return nil
+}
+
+// WriteTo writes the values to w as an LSV. It returns the number of bytes
+// written and ErrUnrepresentable if a value cannot be written in a way that is
+// read back unchanged.
+func (l List[T]) WriteTo(w io.Writer) (int64, error) {
+	format, err := l.formatter()
+	if err != nil {
+		return 0, err
+	}
+
+	values := make([]string, len(l.Values))
+	for i, v := range l.Values {
+		if values[i], err = format(v); err != nil {
+			return 0, err
+		}
+	}
+
+	cw := &countingWriter{w: w}
+	err = NewCustomWriter(cw, l.params()).WriteAll(values)
+	return cw.n, err
+}
+
+// ReadFrom reads all values from the LSV in r and appends them to the list. It
+// returns the number of bytes read. On error, the values are not modified.
+func (l *List[T]) ReadFrom(r io.Reader) (int64, error) {
+	values, n, err := l.read(r)
+	if err != nil {
+		return n, err
+	}
+	l.Values = append(l.Values, values...)
+	return n, nil
+}
+
+// read reads and parses all values from r and returns them along with the
+// number of bytes read.
+func (l *List[T]) read(r io.Reader) ([]T, int64, error) {
+	parse, err := l.parser()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cr := &countingReader{r: r}
+	lr := NewCustomReader(cr, l.params())
+	var values []T
+	for {
+		rec, err := lr.ReadRecord()
+		if err == io.EOF {
+			return values, cr.n, nil
+		} else if err != nil {
+			return nil, cr.n, err
+		}
+
+		v, err := parse(rec.Value)
+		if err != nil {
+			start, _ := lr.ValueRange()
+			return nil, cr.n, &DecodeError{
+				Line:  start.Line,
+				Value: rec.Value,
+				Type:  reflect.TypeOf((*T)(nil)).Elem(),
+				Err:   err,
+			}
+		}
+		values = append(values, v)
+	}
+}
+
+// params returns the Parameters of the list or the default Parameters if they
+// are not set.
+func (l *List[T]) params() Parameters {
+	if l.Parameters == (Parameters{}) {
+		return DefaultParameters()
+	}
+	return l.Parameters
+}
+
+// parser returns Parse or, if it is nil, a function that converts the value
+// like Reader.Decode.
+func (l *List[T]) parser() (func(string) (T, error), error) {
+	if l.Parse != nil {
+		return l.Parse, nil
+	}
+
+	convert := converter(reflect.TypeOf((*T)(nil)).Elem())
+	if convert == nil {
+		return nil, ErrDecodeTarget
+	}
+	return func(value string) (T, error) {
+		var v T
+		err := convert(value, reflect.ValueOf(&v).Elem())
+		return v, err
+	}, nil
+}
+
+// formatter returns Format or, if it is nil, a function that converts the value
+// like Writer.Encode.
+func (l *List[T]) formatter() (func(T) (string, error), error) {
+	if l.Format != nil {
+		return func(v T) (string, error) { return l.Format(v), nil }, nil
+	}
+
+	format := formatter(reflect.TypeOf((*T)(nil)).Elem())
+	if format == nil {
+		return nil, ErrEncodeSource
+	}
+	return func(v T) (string, error) {
+		return format(reflect.ValueOf(&v).Elem())
+	}, nil
+}
+
+// countingWriter counts the bytes written to the underlying io.Writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// countingReader counts the bytes read from the underlying io.Reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
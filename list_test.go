@@ -0,0 +1,175 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Interface checks.
+var (
+	_ encoding.TextMarshaler   = List[int]{}
+	_ encoding.TextUnmarshaler = &List[int]{}
+	_ io.WriterTo              = List[int]{}
+	_ io.ReaderFrom            = &List[int]{}
+)
+
+// Tests that the zero List reads and writes values using the same conversions
+// as Decode and Encode.
+func TestList_Default(t *testing.T) {
+	src := "10.0.0.0/8 # private\n\n\"::1/128\"\n"
+	var l List[netip.Prefix]
+	if err := l.UnmarshalText([]byte(src)); err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	}
+
+	expected := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("::1/128")}
+	if !reflect.DeepEqual(expected, l.Values) {
+		t.Errorf("Unexpected values.\nexpected: %v\nreceived: %v",
+			expected, l.Values)
+	}
+
+	text, err := l.MarshalText()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %+v", err)
+	}
+	if expectedText := "10.0.0.0/8\n::1/128\n"; string(text) != expectedText {
+		t.Errorf("Unexpected text.\nexpected: %q\nreceived: %q",
+			expectedText, text)
+	}
+}
+
+// Tests that List uses the Parse and Format functions and the Parameters.
+func TestList_Custom(t *testing.T) {
+	l := List[int]{
+		Parse: func(s string) (int, error) {
+			return strconv.Atoi(strings.TrimPrefix(s, "#"))
+		},
+		Format: func(i int) string { return "#" + strconv.Itoa(i) },
+		Parameters: Parameters{
+			Comment: ';', Raw: '\'', Escape: '/', TrimLeadingSpace: true},
+	}
+
+	if err := l.UnmarshalText([]byte("#1 ; one\n  #2\n")); err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	}
+	if expected := []int{1, 2}; !reflect.DeepEqual(expected, l.Values) {
+		t.Errorf("Unexpected values.\nexpected: %v\nreceived: %v",
+			expected, l.Values)
+	}
+
+	l.Values = append(l.Values, -3)
+	text, err := l.MarshalText()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %+v", err)
+	}
+	if expected := "#1\n#2\n#-3\n"; string(text) != expected {
+		t.Errorf("Unexpected text.\nexpected: %q\nreceived: %q",
+			expected, text)
+	}
+}
+
+// Tests that List.ReadFrom appends the values and List.WriteTo writes them,
+// both returning the number of bytes.
+func TestList_ReadFrom_WriteTo(t *testing.T) {
+	l := List[string]{Values: []string{"a"}}
+	src := "b\n\"\"\n# c\n"
+	n, err := l.ReadFrom(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Failed to read: %+v", err)
+	}
+	if n != int64(len(src)) {
+		t.Errorf("Unexpected bytes read.\nexpected: %d\nreceived: %d",
+			len(src), n)
+	}
+	expected := []string{"a", "b", ""}
+	if !reflect.DeepEqual(expected, l.Values) {
+		t.Errorf("Unexpected values.\nexpected: %q\nreceived: %q",
+			expected, l.Values)
+	}
+
+	var buff bytes.Buffer
+	n, err = l.WriteTo(&buff)
+	if err != nil {
+		t.Fatalf("Failed to write: %+v", err)
+	}
+	if expectedText := "a\nb\n\"\"\n"; buff.String() != expectedText {
+		t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+			expectedText, buff.String())
+	}
+	if n != int64(buff.Len()) {
+		t.Errorf("Unexpected bytes written.\nexpected: %d\nreceived: %d",
+			buff.Len(), n)
+	}
+}
+
+// Tests that a List can be used as a field of a struct encoded as JSON.
+func TestList_JSON(t *testing.T) {
+	type config struct {
+		Allow List[netip.Prefix] `json:"allow"`
+	}
+
+	var c config
+	err := json.Unmarshal([]byte(`{"allow":"10.0.0.0/8\n# x\n::/0"}`), &c)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	}
+	if len(c.Allow.Values) != 2 || c.Allow.Values[1].String() != "::/0" {
+		t.Errorf("Unexpected values: %v", c.Allow.Values)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %+v", err)
+	}
+	if expected := `{"allow":"10.0.0.0/8\n::/0\n"}`; string(data) != expected {
+		t.Errorf("Unexpected JSON.\nexpected: %s\nreceived: %s",
+			expected, data)
+	}
+}
+
+// Tests that List returns the expected errors and does not modify the values
+// on error.
+func TestList_Error(t *testing.T) {
+	l := List[int]{Values: []int{1}}
+	err := l.UnmarshalText([]byte("2\nx\n"))
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Line != 2 || de.Value != "x" {
+		t.Errorf("Unexpected error: %+v", err)
+	}
+
+	_, err = l.ReadFrom(strings.NewReader("\"2\n"))
+	if !errors.Is(err, ErrNoClosingRaw) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrNoClosingRaw, err)
+	}
+	if !reflect.DeepEqual([]int{1}, l.Values) {
+		t.Errorf("Values were modified: %v", l.Values)
+	}
+
+	var c List[complex64]
+	if err = c.UnmarshalText([]byte("1\n")); !errors.Is(err, ErrDecodeTarget) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrDecodeTarget, err)
+	}
+	c.Values = []complex64{1}
+	if _, err = c.MarshalText(); !errors.Is(err, ErrEncodeSource) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrEncodeSource, err)
+	}
+}
@@ -8,60 +8,162 @@
 package lsv
 
 import (
-	"bytes"
 	"encoding/csv"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
 
+// Line is a line of benchmark input that is read as a single value by both
+// lsv and encoding/csv.
 var Line = "this is a value   # This is a comment\n"
 
-func Benchmark_Split(b *testing.B) {
-	line := Line
-	var str strings.Builder
+// benchmarkInput is read by every benchmark that compares lsv to encoding/csv.
+var benchmarkInput = strings.Repeat(Line, 1000)
+
+// benchmarkMixed contains every kind of line: block comments, indented values
+// with inline comments, blank lines, and quoted single and multi-line values.
+var benchmarkMixed = strings.Repeat("# Block comment\nvalue one\n"+
+	"  value two # Inline\n\n\"quoted # value\"\n\"multi\nline\"\n", 200)
+
+// benchmarkSplit runs SplitParams on the input.
+func benchmarkSplit(b *testing.B, input string, p Parameters) {
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
 	for n := 0; n < b.N; n++ {
-		b.StopTimer()
-		str.WriteString(line)
-		buff := bytes.NewBufferString("")
-		buff.WriteString(line)
-		b.StartTimer()
-		data, _ := io.ReadAll(buff)
-		_, err := Split(string(data))
-		if err != nil {
-			b.Errorf("splitter error: %+v", err)
+		if _, err := SplitParams(input, p); err != nil {
+			b.Fatalf("Failed to split: %+v", err)
+		}
+	}
+}
+
+// benchmarkReadAll runs Reader.ReadAll on the input.
+func benchmarkReadAll(b *testing.B, input string, p Parameters) {
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		r := NewCustomReader(strings.NewReader(input), p)
+		if _, err := r.ReadAll(); err != nil {
+			b.Fatalf("Failed to read: %+v", err)
 		}
 	}
 }
 
+func Benchmark_Split(b *testing.B) {
+	benchmarkSplit(b, benchmarkInput, DefaultParameters())
+}
+
 func Benchmark_ReadAll(b *testing.B) {
-	line := Line
-	var str strings.Builder
+	benchmarkReadAll(b, benchmarkInput, DefaultParameters())
+}
+
+func Benchmark_CSV(b *testing.B) {
+	b.SetBytes(int64(len(benchmarkInput)))
+	b.ReportAllocs()
 	for n := 0; n < b.N; n++ {
-		b.StopTimer()
-		str.WriteString(line)
-		strReader := strings.NewReader(str.String())
-		r := NewReader(strReader)
-		b.StartTimer()
-		_, err := r.ReadAll()
+		r := csv.NewReader(strings.NewReader(benchmarkInput))
+		if _, err := r.ReadAll(); err != nil {
+			b.Fatalf("Failed to read: %+v", err)
+		}
+	}
+}
+
+func Benchmark_Split_Strict(b *testing.B) {
+	benchmarkSplit(b, benchmarkInput, strictParameters())
+}
+
+func Benchmark_ReadAll_Strict(b *testing.B) {
+	benchmarkReadAll(b, benchmarkInput, strictParameters())
+}
+
+func Benchmark_Split_Mixed(b *testing.B) {
+	benchmarkSplit(b, benchmarkMixed, DefaultParameters())
+}
+
+func Benchmark_ReadAll_Mixed(b *testing.B) {
+	benchmarkReadAll(b, benchmarkMixed, DefaultParameters())
+}
+
+// Tests that lsv and encoding/csv read the same values from the benchmark
+// input, so that the benchmarks compare the same work.
+func Test_benchmarkInput(t *testing.T) {
+	values, err := Split(benchmarkInput)
+	if err != nil {
+		t.Fatalf("Failed to split: %+v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(benchmarkInput)).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %+v", err)
+	}
+
+	if len(values) != len(records) {
+		t.Fatalf("Different number of values.\nlsv: %d\ncsv: %d",
+			len(values), len(records))
+	}
+	for i, rec := range records {
+		if len(rec) != 1 || !strings.HasPrefix(rec[0], values[i]) {
+			t.Errorf("Value %d differs.\nlsv: %q\ncsv: %q", i, values[i], rec)
+		}
+	}
+}
+
+func Benchmark_ReadBytes(b *testing.B) {
+	b.SetBytes(int64(len(benchmarkMixed)))
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		r := NewReader(strings.NewReader(benchmarkMixed))
+		r.ReuseValue = true
+		for {
+			if _, err := r.ReadBytes(); err == io.EOF {
+				break
+			} else if err != nil {
+				b.Fatalf("Failed to read: %+v", err)
+			}
+		}
+	}
+}
+
+func Benchmark_ParallelReadAll(b *testing.B) {
+	input := strings.Repeat(benchmarkMixed, 200)
+	r := strings.NewReader(input)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		_, err := ParallelReadAll(r, r.Size(), DefaultParameters(), 0)
 		if err != nil {
-			b.Errorf("splitter error: %+v", err)
+			b.Fatalf("Failed to read: %+v", err)
 		}
 	}
 }
 
-func Benchmark_CSV(b *testing.B) {
-	line := Line
-	var str strings.Builder
+func Benchmark_ReadAll_Large(b *testing.B) {
+	input := strings.Repeat(benchmarkMixed, 200)
+	benchmarkReadAll(b, input, DefaultParameters())
+}
+
+func Benchmark_MmapReader(b *testing.B) {
+	input := strings.Repeat(benchmarkMixed, 200)
+	path := filepath.Join(b.TempDir(), "list.lsv")
+	if err := os.WriteFile(path, []byte(input), 0o600); err != nil {
+		b.Fatalf("Failed to write file: %+v", err)
+	}
+
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
 	for n := 0; n < b.N; n++ {
-		b.StopTimer()
-		str.WriteString(line)
-		strReader := strings.NewReader(str.String())
-		r := csv.NewReader(strReader)
-		b.StartTimer()
-		_, err := r.ReadAll()
+		m, err := OpenMmap(path, DefaultParameters())
 		if err != nil {
-			b.Errorf("splitter error: %+v", err)
+			b.Fatalf("Failed to open: %+v", err)
+		}
+		for {
+			if _, err = m.ReadBytes(); err == io.EOF {
+				break
+			} else if err != nil {
+				b.Fatalf("Failed to read: %+v", err)
+			}
 		}
+		m.Close()
 	}
 }
@@ -0,0 +1,32 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bytes"
+)
+
+// Marshal returns the LSV encoding of the values using the default Parameters.
+// It returns ErrUnrepresentable if any value cannot be encoded in a way that is
+// read back unchanged.
+//
+// Marshal and [Unmarshal] are inverses: for any values that Marshal encodes
+// without error, Unmarshal returns the same values.
+func Marshal(values []string) ([]byte, error) {
+	var buff bytes.Buffer
+	if err := NewWriter(&buff).WriteAll(values); err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+// Unmarshal parses the LSV-encoded data using the default Parameters and
+// returns its values. It returns the same errors as [Reader.ReadAll].
+func Unmarshal(data []byte) ([]string, error) {
+	return NewReader(bytes.NewReader(data)).ReadAll()
+}
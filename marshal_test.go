@@ -0,0 +1,119 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// allStrings returns every string of length 1 to n made of runes from the
+// alphabet.
+func allStrings(alphabet []rune, n int) []string {
+	values := []string{""}
+	var all []string
+	for i := 0; i < n; i++ {
+		var next []string
+		for _, v := range values {
+			for _, c := range alphabet {
+				next = append(next, v+string(c))
+			}
+		}
+		all = append(all, next...)
+		values = next
+	}
+	return all
+}
+
+// Tests that every short value made of characters that are significant to LSV
+// is either read back unchanged after Marshal or is rejected with
+// ErrUnrepresentable.
+func TestMarshal_RoundTrip(t *testing.T) {
+	alphabet := []rune{'a', ' ', '\t', '\n', '\r', '"', '#', '\\'}
+
+	var representable []string
+	var unrepresentable int
+	for _, value := range allStrings(alphabet, 4) {
+		data, err := Marshal([]string{value})
+		if errors.Is(err, ErrUnrepresentable) {
+			unrepresentable++
+			continue
+		} else if err != nil {
+			t.Fatalf("Unexpected Marshal error for %q: %+v", value, err)
+		}
+
+		out, err := Unmarshal(data)
+		if err != nil {
+			t.Errorf("Failed to Unmarshal %q (from %q): %+v", data, value, err)
+		} else if len(out) != 1 || out[0] != value {
+			t.Errorf("Value changed after round trip through %q."+
+				"\nexpected: %q\nreceived: %q", data, []string{value}, out)
+		}
+
+		representable = append(representable, value)
+	}
+
+	t.Logf("%d of %d values are unrepresentable.",
+		unrepresentable, unrepresentable+len(representable))
+
+	data, err := Marshal(representable)
+	if err != nil {
+		t.Fatalf("Failed to Marshal all values: %+v", err)
+	}
+	out, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Failed to Unmarshal all values: %+v", err)
+	}
+	if !reflect.DeepEqual(representable, out) {
+		t.Errorf("Values changed after round trip of all values.")
+	}
+}
+
+// Tests that Marshal returns ErrUnrepresentable for values that cannot be read
+// back unchanged.
+func TestMarshal_Unrepresentable(t *testing.T) {
+	values := []string{
+		" a\\",        // Escape before the closing Raw character
+		"a\n\\",       // Escape before the closing Raw character
+		"\"a#b\nc",    // Comment after a Raw character on the first line
+		" a\n\"b #c ", // Comment after a Raw character on another line
+	}
+
+	for _, value := range values {
+		data, err := Marshal([]string{"a", value})
+		if !errors.Is(err, ErrUnrepresentable) {
+			t.Errorf("Expected ErrUnrepresentable for %q, received %v (%q).",
+				value, err, data)
+		}
+	}
+}
+
+// Tests that Unmarshal returns the expected values and errors.
+func TestUnmarshal(t *testing.T) {
+	for _, tt := range readTests {
+		if tt.Comment != 0 || tt.Raw != 0 || tt.Escape != 0 || tt.NoTrim {
+			continue
+		}
+
+		t.Run(tt.Name, func(t *testing.T) {
+			out, err := Unmarshal([]byte(tt.Input))
+			if tt.Error != nil {
+				if !errors.Is(err, tt.Error) {
+					t.Fatalf("Unmarshal error mismatch:"+
+						"\nexpected: %v\nreceived: %v", tt.Error, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Unexpected Unmarshal error: %+v", err)
+			} else if !reflect.DeepEqual(out, tt.Output) {
+				t.Fatalf("Unmarshal unexpected output:"+
+					"\nexpected: %q\nreceived: %q", tt.Output, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"io"
+	"os"
+)
+
+// MmapReader reads values from a file opened with [OpenMmap]. On Linux, the
+// file is memory-mapped and values are returned as slices of the mapping
+// without being copied. On other systems, the file is read with a buffered
+// [Reader].
+type MmapReader struct {
+	r      *Reader
+	data   []byte   // The read-only mapping of the file, if mapped
+	f      *os.File // The open file, if not mapped
+	closed bool
+}
+
+// OpenMmap opens the named file for reading values using the Parameters. On
+// Linux, the file is mapped read-only into memory, which avoids copying the
+// file into a buffer and each value out of it. The file must not be truncated
+// while it is mapped, since accessing the mapping past the new end of the file
+// raises SIGBUS and crashes the program. The MmapReader must be closed to
+// release the mapping or file.
+func OpenMmap(path string, p Parameters) (*MmapReader, error) {
+	if !p.Verify() {
+		return nil, ErrInvalidParams
+	}
+
+	m, err := openMmap(path, p)
+	if err != nil {
+		return nil, err
+	}
+	m.r.FileName = path
+	return m, nil
+}
+
+// Read reads one value from the file and returns a copy of it. It returns the
+// same errors as [Reader.Read].
+func (m *MmapReader) Read() (string, error) {
+	value, err := m.ReadBytes()
+	return string(value), err
+}
+
+// ReadBytes reads one value from the file. Unless the value contains escapes or
+// spans several lines, the returned slice is a slice of the mapping that is
+// valid until the MmapReader is closed and that must not be modified, since
+// the mapping is read-only. Accessing such a slice after the file is truncated
+// raises SIGBUS, as described in [OpenMmap]. Otherwise, and on systems without
+// the mapping, the slice is only valid until the next call to ReadBytes, like
+// with [Reader.ReuseValue]. ReadBytes returns the same errors as [Reader.Read]
+// and [os.ErrClosed] once the MmapReader is closed.
+func (m *MmapReader) ReadBytes() ([]byte, error) {
+	if m.closed {
+		return nil, os.ErrClosed
+	}
+
+	rec, inBuf, err := m.r.scanRecord(false)
+	if err != nil {
+		return nil, err
+	} else if inBuf {
+		return m.r.buf, nil
+	}
+	return m.valueBytes(rec.Value), nil
+}
+
+// ReadAll reads all the remaining values from the file and returns copies of
+// them. It returns the same results as [Reader.ReadAll].
+func (m *MmapReader) ReadAll() ([]string, error) {
+	var values []string
+	for {
+		value, err := m.Read()
+		if err == io.EOF {
+			return values, nil
+		} else if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+}
+
+// Close releases the mapping or closes the file. Slices of the mapping
+// returned by ReadBytes must not be used after Close.
+func (m *MmapReader) Close() error {
+	if m.closed {
+		return os.ErrClosed
+	}
+	m.closed = true
+	return m.close()
+}
@@ -0,0 +1,81 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// openMmap opens the file and maps it into memory. Lines are read from a string
+// that shares the memory of the mapping, so values are never copied.
+func openMmap(path string, p Parameters) (*MmapReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// An empty file cannot be mapped
+	var data []byte
+	if size := info.Size(); size > 0 {
+		if int64(int(size)) != size {
+			return nil, &os.PathError{
+				Op: "mmap", Path: path, Err: syscall.EFBIG}
+		}
+		data, err = syscall.Mmap(int(f.Fd()), 0, int(size),
+			syscall.PROT_READ, syscall.MAP_SHARED)
+		if err != nil {
+			return nil, &os.PathError{Op: "mmap", Path: path, Err: err}
+		}
+
+		// The values are read in order, so the kernel can read ahead
+		_ = syscall.Madvise(data, syscall.MADV_SEQUENTIAL)
+	}
+
+	r := &Reader{Parameters: p, r: &stringLines{bytesString(data)}}
+	return &MmapReader{r: r, data: data}, nil
+}
+
+// valueBytes returns the value, which is a slice of the mapping, as a byte
+// slice without copying it.
+func (m *MmapReader) valueBytes(value string) []byte {
+	return stringBytes(value)
+}
+
+// close unmaps the file.
+func (m *MmapReader) close() error {
+	if m.data == nil {
+		return nil
+	}
+	data := m.data
+	m.data = nil
+	return syscall.Munmap(data)
+}
+
+// stringHeader is the memory layout of a string.
+type stringHeader struct {
+	data unsafe.Pointer
+	len  int
+}
+
+// stringBytes returns a byte slice that shares the memory of s. The bytes must
+// not be modified.
+func stringBytes(s string) []byte {
+	if s == "" {
+		return nil
+	}
+	data := (*stringHeader)(unsafe.Pointer(&s)).data
+	return unsafe.Slice((*byte)(data), len(s))
+}
@@ -0,0 +1,49 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import "testing"
+
+// Tests that MmapReader.ReadBytes returns values without escapes as slices of
+// the mapping and does not allocate.
+func TestMmapReader_ReadBytes_Mapped(t *testing.T) {
+	input := "# Comment\n  abc # Inline\n\"d e\"\nf \\# g\n\"h\ni\"\n"
+	m, err := OpenMmap(writeTempFile(t, input), DefaultParameters())
+	if err != nil {
+		t.Fatalf("Failed to open: %+v", err)
+	}
+	defer m.Close()
+
+	// Offset of each value in the input, or -1 if it is copied
+	expected := []int{12, 26, -1, -1}
+	for i, offset := range expected {
+		value, err := m.ReadBytes()
+		if err != nil {
+			t.Fatalf("Failed to read value %d: %+v", i, err)
+		}
+		if offset > -1 && &value[0] != &m.data[offset] {
+			t.Errorf("Value %d %q is not a slice of the mapping.", i, value)
+		}
+	}
+
+	input = "abc # Inline\n\"d e\" # Inline\n"
+	m, err = OpenMmap(writeTempFile(t, input), DefaultParameters())
+	if err != nil {
+		t.Fatalf("Failed to open: %+v", err)
+	}
+	defer m.Close()
+	allocs := testing.AllocsPerRun(1, func() {
+		if _, err := m.ReadBytes(); err != nil {
+			t.Fatalf("Failed to read: %+v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Unexpected allocations.\nexpected: %d\nreceived: %.1f",
+			0, allocs)
+	}
+}
@@ -0,0 +1,33 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+//go:build !linux
+
+package lsv
+
+import "os"
+
+// openMmap opens the file and reads it with a buffered Reader, since memory
+// mapping is only supported on Linux.
+func openMmap(path string, p Parameters) (*MmapReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &MmapReader{r: NewCustomReader(f, p), f: f}, nil
+}
+
+// valueBytes copies the value to the buffer of the Reader.
+func (m *MmapReader) valueBytes(value string) []byte {
+	m.r.buf = append(m.r.buf, value...)
+	return m.r.buf
+}
+
+// close closes the file.
+func (m *MmapReader) close() error {
+	return m.f.Close()
+}
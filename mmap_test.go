@@ -0,0 +1,137 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTempFile writes the data to a new file in a temporary directory and
+// returns its path.
+func writeTempFile(t *testing.T, data string) string {
+	path := filepath.Join(t.TempDir(), "list.lsv")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %+v", err)
+	}
+	return path
+}
+
+// Tests that MmapReader returns the same values as Reader.ReadAll for each
+// test, with both MmapReader.ReadBytes and MmapReader.ReadAll.
+func TestOpenMmap(t *testing.T) {
+	for _, tt := range readTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			p := DefaultParameters()
+			p.TrimLeadingSpace = !tt.NoTrim
+			if tt.Comment != 0 {
+				p.Comment = tt.Comment
+			}
+			if tt.Raw != 0 {
+				p.Raw = tt.Raw
+			}
+			if tt.Escape != 0 {
+				p.Escape = tt.Escape
+			}
+			path := writeTempFile(t, tt.Input)
+
+			m, err := OpenMmap(path, p)
+			if tt.Error != nil && errors.Is(err, tt.Error) {
+				return
+			} else if err != nil {
+				t.Fatalf("Failed to open: %+v", err)
+			}
+			var values []string
+			for {
+				value, err := m.ReadBytes()
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					if tt.Error == nil || !errors.Is(err, tt.Error) {
+						t.Fatalf("Unexpected ReadBytes error: %+v", err)
+					}
+					values = tt.Output
+					break
+				}
+				values = append(values, string(value))
+			}
+			if err = m.Close(); err != nil {
+				t.Errorf("Failed to close: %+v", err)
+			}
+			if !reflect.DeepEqual(tt.Output, values) {
+				t.Errorf("Unexpected values.\nexpected: %q\nreceived: %q",
+					tt.Output, values)
+			}
+
+			m, err = OpenMmap(path, p)
+			if err != nil {
+				t.Fatalf("Failed to open: %+v", err)
+			}
+			defer m.Close()
+			values, err = m.ReadAll()
+			if tt.Error != nil {
+				if !errors.Is(err, tt.Error) {
+					t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+						tt.Error, err)
+				}
+			} else if err != nil || !reflect.DeepEqual(tt.Output, values) {
+				t.Errorf("Unexpected ReadAll result.\nexpected: %q"+
+					"\nreceived: %q, %v", tt.Output, values, err)
+			}
+		})
+	}
+}
+
+// Tests that OpenMmap and MmapReader return the expected errors.
+func TestOpenMmap_Error(t *testing.T) {
+	path := writeTempFile(t, "a\n\"b\n")
+
+	if _, err := OpenMmap(path, Parameters{}); !errors.Is(
+		err, ErrInvalidParams) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrInvalidParams, err)
+	}
+
+	_, err := OpenMmap(filepath.Join(t.TempDir(), "none.lsv"),
+		DefaultParameters())
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			fs.ErrNotExist, err)
+	}
+
+	m, err := OpenMmap(path, DefaultParameters())
+	if err != nil {
+		t.Fatalf("Failed to open: %+v", err)
+	}
+	_, err = m.ReadAll()
+	var pe *ParseError
+	if !errors.As(err, &pe) || !errors.Is(err, ErrNoClosingRaw) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrNoClosingRaw, err)
+	} else if pe.File != path {
+		t.Errorf("Unexpected file.\nexpected: %s\nreceived: %s",
+			path, pe.File)
+	}
+
+	if err = m.Close(); err != nil {
+		t.Errorf("Failed to close: %+v", err)
+	}
+	if _, err = m.ReadBytes(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Unexpected error after Close."+
+			"\nexpected: %v\nreceived: %v", os.ErrClosed, err)
+	}
+	if err = m.Close(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Unexpected error for second Close."+
+			"\nexpected: %v\nreceived: %v", os.ErrClosed, err)
+	}
+}
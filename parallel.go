@@ -0,0 +1,186 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// minChunkSize is the smallest part of the input read by one goroutine in
+// ParallelReadAll, so that small inputs are not split.
+const minChunkSize = 256 << 10
+
+// ParallelReadAll reads all the values from the first size bytes of r using
+// the Parameters, splitting the input into chunks that are read concurrently
+// by up to the given number of workers. If workers is less than one,
+// [runtime.GOMAXPROCS] workers are used. It returns the values in their
+// original order and the same results and errors as [Reader.ReadAll],
+// including the positions in any ParseError.
+//
+// The input is split after a newline, which is a safe line boundary unless it
+// is inside a raw string literal. Each chunk is read as if it starts outside a
+// literal. When a chunk ends inside a raw string literal, the start of the
+// next chunk is not a value boundary, so the rest of the input is read again
+// from the start of the literal until it is closed. A literal that spans
+// several chunks is therefore read by a single goroutine.
+func ParallelReadAll(
+	r io.ReaderAt, size int64, p Parameters, workers int) ([]string, error) {
+	if !p.Verify() {
+		return nil, ErrInvalidParams
+	}
+
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	n := size / minChunkSize
+	if n > int64(workers) {
+		n = int64(workers)
+	}
+
+	bounds, err := chunkBounds(r, size, int(n))
+	if err != nil {
+		return nil, err
+	}
+	return readChunks(r, bounds, p)
+}
+
+// chunkBounds returns the offsets of the start of up to n chunks of the first
+// size bytes of r, followed by size. Each chunk after the first starts directly
+// after a newline near an even split of the input.
+func chunkBounds(r io.ReaderAt, size int64, n int) ([]int64, error) {
+	bounds := []int64{0}
+	buf := make([]byte, defaultBufSize)
+	for k := 1; k < n; k++ {
+		// Search for a newline starting at the byte before the split so that
+		// a split directly after a newline is kept
+		off := size * int64(k) / int64(n)
+		if last := bounds[len(bounds)-1]; off <= last {
+			off = last + 1
+		}
+		for off--; off < size; off += int64(len(buf)) {
+			m, err := r.ReadAt(buf[:min64(int64(len(buf)), size-off)], off)
+			if i := bytes.IndexByte(buf[:m], '\n'); i > -1 {
+				off += int64(i + 1)
+				break
+			} else if err != nil && err != io.EOF {
+				return nil, err
+			} else if m == 0 {
+				off = size
+			}
+		}
+		if off >= size {
+			break
+		}
+		bounds = append(bounds, off)
+	}
+	return append(bounds, size), nil
+}
+
+// min64 returns the smaller of a and b.
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// chunkResult is the result of reading a chunk of the input.
+type chunkResult struct {
+	values []string
+	err    error
+
+	// lines is the number of lines read. tail and tailLine are the offset and
+	// the number of lines read at the end of the last value, from where the
+	// input is read again if the chunk ends inside a raw string literal.
+	lines    int
+	tail     int64
+	tailLine int
+}
+
+// readChunks reads the chunks between the bounds concurrently and joins their
+// values in order, reading the input again where a chunk ends inside a raw
+// string literal.
+func readChunks(r io.ReaderAt, bounds []int64, p Parameters) ([]string, error) {
+	results := make([]chunkResult, len(bounds)-1)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = readChunk(r, bounds[i], bounds[i+1], 0, p)
+		}(i)
+	}
+	wg.Wait()
+
+	// Each chunk is read from line 0, so its lines are shifted by the number
+	// of lines before it. Chunks read again start from the correct line.
+	var values []string
+	res := results[0]
+	for i := 0; ; i++ {
+		values = append(values, res.values...)
+		last := i == len(results)-1
+		if res.err != nil &&
+			(last || !errors.Is(res.err, ErrNoClosingRaw)) {
+			return nil, res.err
+		} else if last {
+			return values, nil
+		}
+
+		if res.err == nil {
+			next := results[i+1]
+			next.shift(res.lines)
+			res = next
+		} else {
+			// The next chunk starts inside a raw string literal
+			res = readChunk(r, res.tail, bounds[i+2], res.tailLine, p)
+		}
+	}
+}
+
+// readChunk reads the values between the start and end offset of r. The line
+// is the number of lines before the start.
+func readChunk(
+	r io.ReaderAt, start, end int64, line int, p Parameters) chunkResult {
+	cr := &Reader{
+		Parameters: p,
+		r:          newBufferedLines(io.NewSectionReader(r, start, end-start)),
+		line:       line,
+		offset:     start,
+	}
+
+	var res chunkResult
+	for {
+		res.tail, res.tailLine = cr.offset, cr.line
+		value, err := cr.readValue()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			res.err = err
+			break
+		}
+		res.values = append(res.values, value)
+	}
+	res.lines = cr.line
+	return res
+}
+
+// shift adds the number of lines before the chunk to the line numbers of the
+// result.
+func (res *chunkResult) shift(lines int) {
+	res.lines += lines
+	res.tailLine += lines
+	var pe *ParseError
+	if errors.As(res.err, &pe) {
+		pe.StartLine += lines
+		pe.Line += lines
+	}
+}
@@ -0,0 +1,141 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// Tests that readChunks returns the same values and errors as Reader.ReadAll
+// for every way of splitting inputs with multi-line raw string literals and
+// errors into chunks.
+func Test_readChunks(t *testing.T) {
+	inputs := []string{
+		"",
+		"a\nb\nc",
+		"a\n\"b\nc\nd\"\ne\n",
+		"\"a\n#b\n\n\"c\n\"\n\"d\ne\"\n",
+		"# a\n\"b\\\"\nc\" # d\n\"e\"\n\"f",
+		"a\n\"b\nc\n\"d\n",
+		"a\n\n\"b\nc # d\n\nf\"\n\"g\\q\"\nh \\q\n",
+		"[a]\nb\n[c\nd\n\"[e\n\"\n",
+	}
+
+	alphabet := []string{"a", "\n", "\"", "\\", "#"}
+	for n := 1; n <= 4; n++ {
+		inputs = append(inputs, combinations(alphabet, n)...)
+	}
+	params := []Parameters{DefaultParameters(), strictParameters(),
+		sequenceParameters(false), sequenceParameters(true),
+		sectionParameters(false), sectionParameters(true)}
+
+	for _, p := range params {
+		for _, input := range inputs {
+			expected, expectedErr := NewCustomReader(
+				strings.NewReader(input), p).ReadAll()
+
+			r := strings.NewReader(input)
+			for n := 1; n <= len(input)+1; n++ {
+				bounds, err := chunkBounds(r, r.Size(), n)
+				if err != nil {
+					t.Fatalf("Failed to get bounds for %q: %+v", input, err)
+				}
+				values, err := readChunks(r, bounds, p)
+				if !reflect.DeepEqual(expected, values) ||
+					!reflect.DeepEqual(expectedErr, err) {
+					t.Errorf("Different results for %q in chunks %d (%+v)."+
+						"\nReadAll:     %q, %v\nreadChunks:  %q, %v",
+						input, bounds, p, expected, expectedErr, values, err)
+				}
+			}
+		}
+	}
+}
+
+// Tests that chunkBounds splits the input directly after newlines.
+func Test_chunkBounds(t *testing.T) {
+	type test struct {
+		input    string
+		n        int
+		expected []int64
+	}
+	tests := []test{
+		{"", 4, []int64{0, 0}},
+		{"abc", 3, []int64{0, 3}},
+		{"a\nb\nc\nd\n", 4, []int64{0, 2, 4, 6, 8}},
+		{"a\nb\nc\nd\n", 2, []int64{0, 4, 8}},
+		{"abcdef\ng\nh", 3, []int64{0, 7, 9, 10}},
+		{"\n\n\n", 10, []int64{0, 1, 2, 3}},
+		{strings.Repeat("a", 9000) + "\nb", 2, []int64{0, 9001, 9002}},
+	}
+
+	for i, tt := range tests {
+		r := strings.NewReader(tt.input)
+		bounds, err := chunkBounds(r, r.Size(), tt.n)
+		if err != nil {
+			t.Errorf("Failed to get bounds (%d): %+v", i, err)
+		} else if !reflect.DeepEqual(tt.expected, bounds) {
+			t.Errorf("Unexpected bounds for %q in %d chunks (%d)."+
+				"\nexpected: %d\nreceived: %d",
+				tt.input, tt.n, i, tt.expected, bounds)
+		}
+	}
+}
+
+// Tests that ParallelReadAll returns the same values as Reader.ReadAll for an
+// input large enough to be split between workers.
+func TestParallelReadAll(t *testing.T) {
+	input := strings.Repeat("# Comment\nvalue # Inline\n\n\"multi\nline\"\n"+
+		"\""+strings.Repeat("long\n", 10000)+"\"\n", 20)
+	expected, err := NewReader(strings.NewReader(input)).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read all: %+v", err)
+	}
+
+	for _, workers := range []int{0, 1, 4, 16} {
+		values, err := ParallelReadAll(strings.NewReader(input),
+			int64(len(input)), DefaultParameters(), workers)
+		if err != nil {
+			t.Errorf("Failed to read with %d workers: %+v", workers, err)
+		} else if !reflect.DeepEqual(expected, values) {
+			t.Errorf("Unexpected values with %d workers."+
+				"\nexpected: %d values\nreceived: %d values",
+				workers, len(expected), len(values))
+		}
+	}
+}
+
+// Tests that ParallelReadAll returns the expected errors.
+func TestParallelReadAll_Error(t *testing.T) {
+	_, err := ParallelReadAll(
+		strings.NewReader("a\n"), 2, Parameters{}, 1)
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrInvalidParams, err)
+	}
+
+	readErr := errors.New("read error")
+	r := iotest.ErrReader(readErr)
+	_, err = ParallelReadAll(readerAt{r}, 1<<20, DefaultParameters(), 4)
+	if !errors.Is(err, readErr) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			readErr, err)
+	}
+}
+
+// readerAt is an io.ReaderAt that reads from an io.Reader, ignoring the offset.
+type readerAt struct{ r io.Reader }
+
+func (ra readerAt) ReadAt(p []byte, _ int64) (int, error) {
+	return ra.r.Read(p)
+}
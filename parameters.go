@@ -8,6 +8,7 @@
 package lsv
 
 import (
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -17,6 +18,8 @@ const (
 	defaultComment = '#'
 	defaultRaw     = '"'
 	defaultEscape  = '\\'
+
+	defaultSeparator = '='
 )
 
 // Parameters contains customizable parameters for reading LSV files.
@@ -46,6 +49,53 @@ type Parameters struct {
 	// If TrimLeadingSpace is true, leading white space in a field is ignored.
 	// This is true by default.
 	TrimLeadingSpace bool
+
+	// Strict enables the strict escape grammar, in which every source has
+	// exactly one interpretation:
+	//
+	//   - The Escape character can only be followed by the Escape, Comment, or
+	//     Raw character, both in unquoted values and in raw string literals.
+	//     The pair is replaced by the second character. Any other Escape
+	//     character, including one at the end of a line, is an error.
+	//   - A raw string literal ends at the first unescaped Raw character, which
+	//     can only be followed by whitespace and an optional comment.
+	//   - An unescaped Raw character in an unquoted value, other than its first
+	//     character, is part of the value.
+	//
+	// Errors are returned as a ParseError wrapping ErrInvalidEscape or
+	// ErrTextAfterRaw. This is false by default.
+	Strict bool
+
+	// EscapeSequences enables C-style escape sequences in raw string literals.
+	// The Escape character followed by n, t, r, or 0 is a newline, tab,
+	// carriage return, or NUL character; followed by xHH, it is the byte with
+	// the hexadecimal value HH; and followed by uXXXX or U00XXXXXX, it is the
+	// Unicode character with that hexadecimal code point. An escaped Escape or
+	// Raw character is that character, and a Raw character preceded by an odd
+	// number of Escape characters is escaped. In strict mode, any other escape
+	// sequence is an error; otherwise, it is read literally. When set, the
+	// Comment and Raw characters cannot be any of the characters that start an
+	// escape sequence. This is false by default.
+	EscapeSequences bool
+
+	// Separator is the character between the key and the value of a key/value
+	// pair read by a KVReader or written by a KVWriter. It must be a valid
+	// delimiter that is unique from Comment, Raw, and Escape. If it is 0, the
+	// equals sign (=) is used. It is not used by Reader and Writer.
+	Separator rune
+
+	// Sections enables section headers, which group the values that follow
+	// them. A section header is a line that starts with an opening square
+	// bracket ([) and ends with a closing square bracket (]), excluding
+	// leading whitespace and an inline comment. The text between the brackets
+	// is the section name, taken literally with its surrounding whitespace
+	// trimmed. A section header that is not closed is an error returned as a
+	// ParseError wrapping ErrSectionNotClosed. An unquoted value that starts
+	// with an opening square bracket must escape it with the Escape
+	// character, which is also a valid escape sequence in strict mode. When
+	// set, the Comment, Raw, and Escape characters cannot be square brackets.
+	// This is false by default.
+	Sections bool
 }
 
 // DefaultParameters returns LSV Parameters with their default values.
@@ -60,12 +110,50 @@ func DefaultParameters() Parameters {
 
 // Verify checks that the Comment, Raw, and Escape are all unique and valid
 // delimiters. A valid delimiter is any valid UTF-8 non-whitespace character
-// that is not equal to 0 or [utf8.RuneError].
+// that is not equal to 0 or [utf8.RuneError]. If EscapeSequences is set, none
+// of them can start an escape sequence, and if Sections is set, none of them
+// can be a square bracket. If the Separator is set, it is checked in the same
+// way.
 func (p Parameters) Verify() bool {
+	if p.EscapeSequences && (strings.ContainsRune(sequenceChars, p.Comment) ||
+		strings.ContainsRune(sequenceChars, p.Raw) ||
+		strings.ContainsRune(sequenceChars, p.Escape)) {
+		return false
+	} else if p.Separator != 0 && !p.validSeparator() {
+		return false
+	} else if p.Sections && (strings.ContainsRune("[]", p.Comment) ||
+		strings.ContainsRune("[]", p.Raw) ||
+		strings.ContainsRune("[]", p.Escape)) {
+		return false
+	}
 	return !(p.Comment == p.Raw || p.Comment == p.Escape || p.Raw == p.Escape ||
 		!validDelim(p.Comment) || !validDelim(p.Raw) || !validDelim(p.Escape))
 }
 
+// verifyKV checks that the Parameters are valid for key/value pairs, including
+// the default Separator if none is set.
+func (p Parameters) verifyKV() bool {
+	return p.Verify() && p.validSeparator()
+}
+
+// validSeparator determines if the Separator, or the default one if it is not
+// set, is a valid delimiter that is unique from the other characters.
+func (p Parameters) validSeparator() bool {
+	sep := p.separator()
+	return validDelim(sep) &&
+		sep != p.Comment && sep != p.Raw && sep != p.Escape &&
+		!(p.EscapeSequences && strings.ContainsRune(sequenceChars, sep)) &&
+		!(p.Sections && strings.ContainsRune("[]", sep))
+}
+
+// separator returns the Separator or the default one if it is not set.
+func (p Parameters) separator() rune {
+	if p.Separator == 0 {
+		return defaultSeparator
+	}
+	return p.Separator
+}
+
 // validDelim determines if the rune is a valid delimiter
 func validDelim(r rune) bool {
 	return r != 0 &&
@@ -77,19 +165,103 @@ func validDelim(r rune) bool {
 // trimComment removes any comment that is not in a raw string literal. It does
 // not trim whitespace.
 func (p Parameters) trimComment(line string, inRaw bool) string {
-	var prev rune
-	for j, char := range line {
-		if p.isComment(char, prev) && !inRaw {
-			line = line[:j]
-			break
-		} else if p.isRaw(char, prev) && inRaw {
-			inRaw = false
+	line, _, _ = p.cutComment(line, inRaw)
+	return line
+}
+
+// cutComment slices line around the first comment that is not in a raw string
+// literal, returning the text before and after the Comment character. If no
+// comment is found, cutComment returns line, "", false. It does not trim
+// whitespace.
+//
+// Only the Raw and Comment characters are searched for, using
+// [strings.IndexRune], which is a byte search for ASCII characters, and runes
+// are only decoded to check the character before a match.
+func (p Parameters) cutComment(
+	line string, inRaw bool) (before, after string, found bool) {
+	var i int
+
+	// Skip to the end of the raw string literal
+	for inRaw {
+		j := strings.IndexRune(line[i:], p.Raw)
+		if j < 0 {
+			return line, "", false
 		}
+		j += i
+		i = j + utf8.RuneLen(p.Raw)
+		inRaw = p.escapedAt(line, j)
+	}
 
-		prev = char
+	for {
+		j := strings.IndexRune(line[i:], p.Comment)
+		if j < 0 {
+			return line, "", false
+		}
+		j += i
+		i = j + utf8.RuneLen(p.Comment)
+		if c, _ := utf8.DecodeLastRuneInString(line[:j]); c != p.Escape {
+			return line[:j], line[i:], true
+		}
 	}
+}
 
-	return line
+// appendUnescapeComments appends the unquoted value to dst with each escaped
+// Comment character replaced with the Comment character and returns the
+// extended slice.
+func (p Parameters) appendUnescapeComments(dst []byte, value string) []byte {
+	escaped := string(p.Escape) + string(p.Comment)
+	for {
+		i := strings.Index(value, escaped)
+		if i == -1 {
+			return append(dst, value...)
+		}
+		dst = append(dst, value[:i]...)
+		value = value[i+utf8.RuneLen(p.Escape):]
+	}
+}
+
+// rawLineEnd finds the closing Raw character in a line of a raw string literal
+// with its comment already removed. Only an unescaped Raw character that is the
+// last non-whitespace character on the line closes the literal. rawLineEnd
+// returns the line and the index of the closing Raw character, or -1 if the
+// literal continues on the next line. In that case, the Escape character before
+// a Raw character at the end of the line is removed, unless EscapeSequences is
+// set, in which case it is decoded with the rest of the literal.
+func (p Parameters) rawLineEnd(line string) (string, int) {
+	trimmed := trimRightSpace(line)
+	last, size := utf8.DecodeLastRuneInString(trimmed)
+	if last != p.Raw {
+		return line, -1
+	}
+
+	j := len(trimmed) - size
+	if !p.escapedAt(line, j) {
+		return line, j
+	} else if !p.EscapeSequences {
+		k := j - utf8.RuneLen(p.Escape)
+		line = line[:k] + line[j:]
+	}
+	return line, -1
+}
+
+// escapedAt determines if the character at index i of s is escaped. It is
+// escaped if the character before it is the Escape character or, if
+// EscapeSequences is set, if it follows an odd number of Escape characters,
+// since each pair of them is an escaped Escape character.
+func (p Parameters) escapedAt(s string, i int) bool {
+	var n int
+	for i > 0 {
+		c, size := utf8.DecodeLastRuneInString(s[:i])
+		if c != p.Escape {
+			break
+		}
+		n++
+		i -= size
+		if !p.EscapeSequences {
+			break
+		}
+	}
+	return n%2 == 1
 }
 
 // isComment determines if the rune is an unescaped comment character.
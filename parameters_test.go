@@ -107,6 +107,97 @@ func TestParameters_Verify(t *testing.T) {
 			Escape:  0,
 		},
 		false,
+	}, {
+		"ValidEscapeSequences",
+		Parameters{
+			Comment:         'A',
+			Raw:             'B',
+			Escape:          'C',
+			EscapeSequences: true,
+		},
+		true,
+	}, {
+		"InvalidEscapeSequencesRaw",
+		Parameters{
+			Comment:         'A',
+			Raw:             'x',
+			Escape:          'C',
+			EscapeSequences: true,
+		},
+		false,
+	}, {
+		"ValidSeparator",
+		Parameters{
+			Comment:   'A',
+			Raw:       'B',
+			Escape:    'C',
+			Separator: 'D',
+		},
+		true,
+	}, {
+		"InvalidMatchSeparatorAndComment",
+		Parameters{
+			Comment:   'A',
+			Raw:       'B',
+			Escape:    'C',
+			Separator: 'A',
+		},
+		false,
+	}, {
+		"InvalidSeparatorDelim",
+		Parameters{
+			Comment:   'A',
+			Raw:       'B',
+			Escape:    'C',
+			Separator: '\t',
+		},
+		false,
+	}, {
+		"ValidSections",
+		Parameters{
+			Comment:  ';',
+			Raw:      '"',
+			Escape:   '\\',
+			Sections: true,
+		},
+		true,
+	}, {
+		"InvalidSectionsComment",
+		Parameters{
+			Comment:  '[',
+			Raw:      '"',
+			Escape:   '\\',
+			Sections: true,
+		},
+		false,
+	}, {
+		"InvalidSectionsEscape",
+		Parameters{
+			Comment:  '#',
+			Raw:      '"',
+			Escape:   ']',
+			Sections: true,
+		},
+		false,
+	}, {
+		"InvalidSectionsSeparator",
+		Parameters{
+			Comment:   '#',
+			Raw:       '"',
+			Escape:    '\\',
+			Separator: ']',
+			Sections:  true,
+		},
+		false,
+	}, {
+		"InvalidEscapeSequencesEscape",
+		Parameters{
+			Comment:         'A',
+			Raw:             'B',
+			Escape:          'n',
+			EscapeSequences: true,
+		},
+		false,
 	},
 	}
 
@@ -228,6 +319,103 @@ func TestParameters_trimComment(t *testing.T) {
 	}
 }
 
+// Tests that Parameters.cutComment returns the expected output for each test.
+func TestParameters_cutComment(t *testing.T) {
+	type test struct {
+		Name          string
+		Line          string
+		InRaw         bool
+		Before, After string
+		Found         bool
+	}
+
+	tests := []test{
+		{"NoComment", "value", false, "value", "", false},
+		{"Comment", "value # comment", false, "value ", " comment", true},
+		{"EscapedComment", `value \# comment`, false, `value \# comment`, "",
+			false},
+		{"CommentInRaw", `value # comment`, true, `value # comment`, "", false},
+		{"CommentAfterRaw", `value" # comment`, true, `value" `, " comment",
+			true},
+		{"OnlyComment", "#comment\n", false, "", "comment\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			before, after, found :=
+				DefaultParameters().cutComment(tt.Line, tt.InRaw)
+			if before != tt.Before || after != tt.After || found != tt.Found {
+				t.Errorf("Unexpected result for %q."+
+					"\nexpected: %q, %q, %t\nreceived: %q, %q, %t", tt.Line,
+					tt.Before, tt.After, tt.Found, before, after, found)
+			}
+		})
+	}
+}
+
+// Tests that Parameters.cutComment only ends a raw string literal at a Raw
+// character preceded by an even number of Escape characters when
+// EscapeSequences is set.
+func TestParameters_cutComment_EscapeSequences(t *testing.T) {
+	p := DefaultParameters()
+	p.EscapeSequences = true
+
+	tests := []struct {
+		Line, Before string
+		Found        bool
+	}{
+		{`a\\" # b`, `a\\" `, true},
+		{`a\\\\" # b`, `a\\\\" `, true},
+		{`a\\\" # b`, `a\\\" # b`, false},
+		{`a\" # b`, `a\" # b`, false},
+	}
+
+	for _, tt := range tests {
+		before, _, found := p.cutComment(tt.Line, true)
+		if before != tt.Before || found != tt.Found {
+			t.Errorf("Unexpected result for %q.\nexpected: %q, %t"+
+				"\nreceived: %q, %t",
+				tt.Line, tt.Before, tt.Found, before, found)
+		}
+	}
+}
+
+// Tests that Parameters.rawLineEnd returns the expected line and index of the
+// closing Raw character with and without EscapeSequences.
+func TestParameters_rawLineEnd(t *testing.T) {
+	type test struct {
+		Name      string
+		Line      string
+		Sequences bool
+		Output    string
+		Index     int
+	}
+
+	tests := []test{
+		{"NoRaw", "a b\n", false, "a b\n", -1},
+		{"Closing", "a b\"  \n", false, "a b\"  \n", 3},
+		{"OnlyRaw", "\"\n", false, "\"\n", 0},
+		{"Escaped", "a\\\"\n", false, "a\"\n", -1},
+		{"EscapedEscape", "a\\\\\"\n", false, "a\\\"\n", -1},
+		{"RawNotLast", "a\" b\n", false, "a\" b\n", -1},
+		{"SequencesEscaped", "a\\\"\n", true, "a\\\"\n", -1},
+		{"SequencesEscapedEscape", "a\\\\\"\n", true, "a\\\\\"\n", 3},
+		{"SequencesOddEscapes", "\\\\\\\"", true, "\\\\\\\"", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			p := DefaultParameters()
+			p.EscapeSequences = tt.Sequences
+			line, i := p.rawLineEnd(tt.Line)
+			if line != tt.Output || i != tt.Index {
+				t.Errorf("Unexpected result for %q.\nexpected: %q, %d"+
+					"\nreceived: %q, %d", tt.Line, tt.Output, tt.Index, line, i)
+			}
+		})
+	}
+}
+
 // Tests that Parameters.isComment returns the expected output for each test.
 func TestParameters_isComment(t *testing.T) {
 	type test struct {
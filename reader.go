@@ -8,11 +8,10 @@
 package lsv
 
 import (
-	"bufio"
 	"errors"
+	"fmt"
 	"io"
 	"strings"
-	"unicode"
 	"unicode/utf8"
 )
 
@@ -23,8 +22,68 @@ var (
 
 	// ErrInvalidParams is returned when the Parameters cannot be verified
 	ErrInvalidParams = errors.New("invalid parameters")
+
+	// ErrInvalidEscape is returned in strict mode when the Escape character is
+	// not followed by the Escape, Comment, or Raw character.
+	ErrInvalidEscape = errors.New("invalid escape sequence")
+
+	// ErrTextAfterRaw is returned in strict mode when the closing Raw character
+	// of a raw string literal is followed by anything other than whitespace
+	// or a comment.
+	ErrTextAfterRaw = errors.New("text after closing raw character")
 )
 
+// A ParseError is returned for parsing errors. Line and column numbers are
+// 1-indexed.
+//
+// ParseError wraps one of the sentinel errors above so that it can be matched
+// using [errors.Is].
+type ParseError struct {
+	File      string // Name of the file being read, if known
+	StartLine int    // Line where the value starts
+	Line      int    // Line where the error occurred
+	Column    int    // Column (1-based byte index) where the error occurred
+	Offset    int64  // Byte offset into the input where the error occurred
+	Err       error  // The actual error
+}
+
+func (e *ParseError) Error() string {
+	var file string
+	if e.File != "" {
+		file = e.File + ": "
+	}
+
+	if e.StartLine != e.Line {
+		return fmt.Sprintf("%svalue on line %d; parse error on line %d, "+
+			"column %d: %v", file, e.StartLine, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("%sparse error on line %d, column %d: %v",
+		file, e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// Position describes a location in the input. Line and column numbers are
+// 1-indexed.
+type Position struct {
+	Line   int   // Line number
+	Column int   // Column (1-based byte index)
+	Offset int64 // Byte offset into the input
+}
+
+// String returns the position in the form "line:column".
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// add returns the position n bytes after p on the same line.
+func (p Position) add(n int) Position {
+	p.Column += n
+	p.Offset += int64(n)
+	return p
+}
+
 // Reader reads values from a LSV-encoded file.
 //
 // The Reader expected input conforming to the LSV structure described in the
@@ -33,14 +92,56 @@ var (
 type Reader struct {
 	Parameters
 
-	r *bufio.Reader
+	// FileName is the name of the file being read. If set, it is included in
+	// any returned ParseError.
+	FileName string
+
+	// ReuseValue controls whether calls to ReadBytes may return a slice sharing
+	// the backing array of the slice returned by the previous call for
+	// performance. By default, each call to ReadBytes returns newly allocated
+	// memory owned by the caller.
+	ReuseValue bool
+
+	r lineReader
+
+	// buf holds the value being read if it cannot be sliced from the input and
+	// comments holds its block comment. Both are reused for every value.
+	buf      []byte
+	comments []string
+
+	// line is the number of complete lines read, column is the number of bytes
+	// read on the current line, and offset is the total number of bytes read.
+	line   int
+	column int
+	offset int64
+
+	// start and end are the positions of the most recently read value.
+	start, end Position
+
+	// If kv is true, every value is preceded by a key and the Separator
+	// character. key and keyPos are the key of the most recently read value
+	// and its position.
+	kv     bool
+	key    string
+	keyPos Position
+
+	// section is the name of the current section and onSection, if set, is
+	// called with the name of every section header read.
+	section   string
+	onSection func(name string)
+
+	// directive, if set, is the name of the include directive. includePath and
+	// includePos are the path and position of the most recently read one.
+	directive   string
+	includePath string
+	includePos  Position
 }
 
 // NewReader returns a new Reader that reads from r.
 func NewReader(r io.Reader) *Reader {
 	return &Reader{
 		Parameters: DefaultParameters(),
-		r:          bufio.NewReader(r),
+		r:          newBufferedLines(r),
 	}
 }
 
@@ -49,7 +150,7 @@ func NewReader(r io.Reader) *Reader {
 func NewCustomReader(r io.Reader, p Parameters) *Reader {
 	return &Reader{
 		Parameters: p,
-		r:          bufio.NewReader(r),
+		r:          newBufferedLines(r),
 	}
 }
 
@@ -60,7 +161,11 @@ func (r *Reader) ReadAll() ([]string, error) {
 	if !r.Verify() {
 		return nil, ErrInvalidParams
 	}
+	return r.readAll()
+}
 
+// readAll is the internal helper function for ReadAll.
+func (r *Reader) readAll() ([]string, error) {
 	var values []string
 
 	for {
@@ -77,8 +182,8 @@ func (r *Reader) ReadAll() ([]string, error) {
 }
 
 // Read reads one value from r. If a raw string literal is started but not
-// closed, Read returns ErrNoClosingRaw. If there is no data left to be read,
-// Read returns io.EOF.
+// closed, Read returns a ParseError wrapping ErrNoClosingRaw. If there is no
+// data left to be read, Read returns io.EOF.
 func (r *Reader) Read() (string, error) {
 	if !r.Verify() {
 		return "", ErrInvalidParams
@@ -86,28 +191,210 @@ func (r *Reader) Read() (string, error) {
 	return r.readValue()
 }
 
+// ReadBytes reads one value from r like [Reader.Read] but returns it as a byte
+// slice. If ReuseValue is true, the slice is only valid until the next call to
+// a read method, like the slice returned by [bufio.Scanner.Bytes], and reading
+// does not allocate once the buffer is large enough for the longest value.
+// ReadBytes returns the same errors as [Reader.Read].
+func (r *Reader) ReadBytes() ([]byte, error) {
+	if !r.Verify() {
+		return nil, ErrInvalidParams
+	}
+
+	rec, inBuf, err := r.scanRecord(false)
+	if err != nil {
+		return nil, err
+	} else if !inBuf {
+		r.buf = append(r.buf, rec.Value...)
+	}
+
+	if r.ReuseValue {
+		return r.buf, nil
+	}
+	return append([]byte{}, r.buf...), nil
+}
+
+// Record is a single value read from an LSV file along with the comments
+// attached to it. It can be written back using [Writer.WriteRecord].
+type Record struct {
+	// ValueComment contains the value and its inline comment. Leading and
+	// trailing whitespace is trimmed from the comment.
+	ValueComment
+
+	// Quoted is true if the value was written as a raw string literal.
+	Quoted bool
+
+	// BlockComment contains the text of each comment line directly above the
+	// value, in order. A blank line between a comment and the value detaches
+	// the comment from the value. Leading and trailing whitespace is trimmed
+	// from each line.
+	BlockComment []string
+}
+
+// ReadAllRecords reads all the remaining records from r. A successful call
+// returns err == nil, not err == io.EOF. Because ReadAllRecords is defined to
+// read until EOF, it does not treat end of file as an error to be reported.
+func (r *Reader) ReadAllRecords() ([]Record, error) {
+	if !r.Verify() {
+		return nil, ErrInvalidParams
+	}
+
+	var records []Record
+
+	for {
+		rec, err := r.readRecord()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, rec)
+	}
+}
+
+// ReadRecord reads one value from r along with its inline comment and any block
+// comment directly above it. Comments that are not attached to a value, such
+// as those at the end of the file, are discarded. ReadRecord returns the same
+// errors as [Reader.Read].
+func (r *Reader) ReadRecord() (Record, error) {
+	if !r.Verify() {
+		return Record{}, ErrInvalidParams
+	}
+	return r.readRecord()
+}
+
+// FieldPos returns the line and column of the start of the most recently read
+// value. For raw string literals, this is the position of the opening Raw
+// character. Numbering of lines and columns starts at 1; columns are counted in
+// bytes, not runes.
+//
+// If FieldPos is called before any value has been read, it returns 0, 0.
+func (r *Reader) FieldPos() (line, column int) {
+	return r.start.Line, r.start.Column
+}
+
+// ValueRange returns the start and end position of the most recently read
+// value. The start is the position of the first byte of the value and the end
+// is the position immediately after its last byte. For raw string literals, the
+// range includes the opening and closing Raw characters and may span several
+// lines.
+//
+// If ValueRange is called before any value has been read, it returns zero
+// positions.
+func (r *Reader) ValueRange() (start, end Position) {
+	return r.start, r.end
+}
+
+// InputOffset returns the input stream byte offset of the current reader
+// position. The offset gives the location of the end of the most recently read
+// value and the beginning of the next one.
+func (r *Reader) InputOffset() int64 {
+	return r.offset
+}
+
+// pos returns the position of the next unread byte.
+func (r *Reader) pos() Position {
+	return Position{Line: r.line + 1, Column: r.column + 1, Offset: r.offset}
+}
+
 // readLine reads the next line (with the trailing end-line). If some bytes were
-// read, then the error is never io.EOF. The result is only valid until the next
-// call to readLine.
+// read, then the error is never io.EOF.
 func (r *Reader) readLine() (string, error) {
-	line, err := r.r.ReadString('\n')
+	line, err := r.r.readLine()
 
-	// If bytes are read, do not return EOF
-	if len(line) > 0 && err == io.EOF {
-		err = nil
+	r.offset += int64(len(line))
+	if strings.HasSuffix(line, "\n") {
+		r.line++
+		r.column = 0
+	} else {
+		r.column += len(line)
 	}
 
 	return line, err
 }
 
+// newParseError returns a ParseError for an error at the position for a value
+// starting on startLine.
+func (r *Reader) newParseError(
+	startLine int, pos Position, err error) *ParseError {
+	return &ParseError{
+		File:      r.FileName,
+		StartLine: startLine,
+		Line:      pos.Line,
+		Column:    pos.Column,
+		Offset:    pos.Offset,
+		Err:       err,
+	}
+}
+
 // readValue is the internal helper function for Read.
 func (r *Reader) readValue() (string, error) {
-	var inRaw bool
-	var line string
-	var rawString strings.Builder
-	var err error
+	rec, inBuf, err := r.scanRecord(false)
+	if err != nil {
+		return "", err
+	} else if inBuf {
+		return string(r.buf), nil
+	}
+	return r.keep(rec.Value), nil
+}
+
+// readRecord is the internal helper function for ReadRecord.
+func (r *Reader) readRecord() (Record, error) {
+	rec, inBuf, err := r.scanRecord(true)
+	if err != nil {
+		return Record{}, err
+	} else if inBuf {
+		rec.Value = string(r.buf)
+	} else {
+		rec.Value = r.keep(rec.Value)
+	}
+	rec.Comment = r.keep(rec.Comment)
+
+	// The block comment shares its memory with the Reader
+	if len(rec.BlockComment) == 0 {
+		rec.BlockComment = nil
+	} else {
+		rec.BlockComment = append([]string(nil), rec.BlockComment...)
+	}
+
+	return rec, nil
+}
+
+// keep returns a copy of s if it is part of a line that is only valid until
+// the next line is read. Strings returned to the caller or kept by the Reader
+// are passed to keep so that they do not share the memory of the buffer.
+func (r *Reader) keep(s string) string {
+	if r.r.temporary() {
+		return strings.Clone(s)
+	}
+	return s
+}
+
+// scanRecord reads the next record without copying the value. If inBuf is
+// true, the value could not be sliced from the input, because it spans several
+// lines or contains escapes, and is in buf instead of in rec.Value. The value
+// and inline comment are only valid until the next line is read. The block
+// comment of the record is only read if comments is true and is stored in
+// comments until the next call.
+func (r *Reader) scanRecord(comments bool) (rec Record, inBuf bool, err error) {
+	r.buf = r.buf[:0]
+	rec.BlockComment = r.comments[:0]
+	defer func() { r.comments = rec.BlockComment[:0] }()
+
+	if r.Strict {
+		return r.scanStrictRecord(rec, comments)
+	}
+
+	var inRaw, found, hasKey bool
+	var line, comment string
+	var start, end Position
 
 	for {
+		// Position of the start of the unprocessed part of the line
+		lineStart := r.pos()
+
 		line, err = r.readLine()
 		if err != nil {
 			break
@@ -116,76 +403,130 @@ func (r *Reader) readValue() (string, error) {
 		if !inRaw {
 			// Trim leading whitespace if not in raw string literal
 			if r.TrimLeadingSpace {
-				line = strings.TrimLeftFunc(line, unicode.IsSpace)
+				n := len(line)
+				line = trimLeftSpace(line)
+				lineStart = lineStart.add(n - len(line))
 			}
 
-			// Skip empty lines or lines with only whitespace
+			// Skip empty lines or lines with only whitespace and drop any
+			// block comment above them
 			if line == "" {
+				rec.BlockComment = rec.BlockComment[:0]
+				continue
+			}
+			start = lineStart
+
+			// A section header ends any block comment above it
+			if r.isSectionLine(line) {
+				if err = r.readSection(line, lineStart); err != nil {
+					return Record{}, false, err
+				}
+				rec.BlockComment = rec.BlockComment[:0]
 				continue
 			}
 
+			// In key/value mode, the key and Separator character come before
+			// the value on any line that is not blank or a comment line
+			if r.kv && r.isKeyLine(line) {
+				line, lineStart, err = r.readKey(line, lineStart)
+				if err != nil {
+					return Record{}, false, err
+				}
+				start, hasKey = lineStart, true
+			}
+
 			// Check if the value is a raw string literal
 			if c, size := utf8.DecodeRuneInString(line); c == r.Raw {
 				inRaw = true
 				line = line[size:]
+				lineStart = lineStart.add(size)
 			}
 		}
 
 		// Trim any comment not in raw string
-		line = r.trimComment(line, inRaw)
-		if line != "" {
-			if inRaw {
-				// If in raw string literal, add to rawString instead of
-				// returning the value so the rest of the value can be read
-
-				var last, prev1, prev2 rune
-				var j, k int
-				for i := len(line); i > 0; {
-					char, size := utf8.DecodeLastRuneInString(line[0:i])
-					i -= size
-					if !unicode.IsSpace(char) && last == 0 {
-						last = char
-						j = i
-					} else if last != 0 && prev1 == 0 {
-						prev1 = char
-						k = i
-					} else if last != 0 && prev1 != 0 && prev2 == 0 {
-						prev2 = char
-						break
-					}
-				}
-
-				if r.isRaw(last, prev1) {
-					rawString.WriteString(line[:j])
-					line = rawString.String()
-					rawString.Reset()
-					inRaw = false
-					break
-				} else if last == r.Raw && prev1 == r.Escape {
-					// Trim escape character
-					line = line[:k] + line[j:]
-				}
-				rawString.WriteString(line)
-			} else {
-				// Trim trailing whitespace
-				line = strings.TrimRightFunc(line, unicode.IsSpace)
+		line, comment, found = r.cutComment(line, inRaw)
+		if inRaw {
+			if line == "" {
+				continue
+			}
 
-				// Replace escaped comments with comment character
-				line = strings.ReplaceAll(
-					line, string(r.Escape)+string(r.Comment), string(r.Comment))
+			// If in raw string literal, add the line to buf instead of
+			// returning the value so the rest of the value can be read. A
+			// literal on a single line without escapes is sliced instead.
+			var j int
+			line, j = r.rawLineEnd(line)
+			if j > -1 {
+				end = lineStart.add(j + utf8.RuneLen(r.Raw))
+				line = line[:j]
+			}
+			if j < 0 || inBuf || (r.EscapeSequences &&
+				strings.ContainsRune(line, r.Escape)) {
+				r.appendRaw(line)
+				inBuf = true
+			}
+			if j > -1 {
+				inRaw = false
+				rec.Quoted = true
+				break
+			}
+		} else {
+			// Trim trailing whitespace
+			line = trimRightSpace(line)
+			end = lineStart.add(len(line))
 
-				if len(line) > 0 {
-					break
+			// Lines without a value are either comment lines, which are kept
+			// as part of the block comment, or blank lines
+			if line == "" && !hasKey {
+				if found && r.readInclude(comment, start) {
+					return Record{}, false, errInclude
+				} else if !found {
+					rec.BlockComment = rec.BlockComment[:0]
+				} else if comments {
+					rec.BlockComment = append(
+						rec.BlockComment, r.keep(trimSpace(comment)))
 				}
+				continue
 			}
+
+			line = r.unescapeSection(line)
+			if strings.ContainsRune(line, r.Escape) {
+				r.buf = r.appendUnescapeComments(r.buf, line)
+				inBuf = true
+			}
+			break
 		}
 	}
 
-	if inRaw {
-		return "", ErrNoClosingRaw
+	// The input ending inside a raw string literal is an error, but any other
+	// error from reading a line is returned as is
+	if err != nil && err != io.EOF {
+		return Record{}, false, err
+	} else if inRaw {
+		return Record{}, false,
+			r.newParseError(start.Line, r.pos(), ErrNoClosingRaw)
 	} else if err != nil {
-		return "", err
+		return Record{}, false, err
 	}
 
-	return line, nil
+	r.start, r.end = start, end
+
+	if !inBuf {
+		rec.Value = line
+	}
+	if found {
+		rec.Comment = trimSpace(comment)
+	}
+
+	return rec, inBuf, nil
+}
+
+// appendRaw appends a line of a raw string literal to buf, decoding its escape
+// sequences if EscapeSequences is set. Escape sequences never span lines, so
+// each line can be decoded on its own.
+func (r *Reader) appendRaw(line string) {
+	if r.EscapeSequences {
+		r.buf = r.appendSequences(r.buf, line)
+	} else {
+		r.buf = append(r.buf, line...)
+	}
 }
@@ -8,11 +8,13 @@
 package lsv
 
 import (
-	"bufio"
+	"errors"
+	"fmt"
 	"io"
 	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"unicode/utf8"
 )
 
@@ -429,7 +431,7 @@ func TestNewReader_Consistency(t *testing.T) {
 
 	expected := &Reader{
 		Parameters: DefaultParameters(),
-		r:          bufio.NewReader(stringReader),
+		r:          newBufferedLines(stringReader),
 	}
 
 	newReader := NewReader(stringReader)
@@ -452,7 +454,7 @@ func TestNewCustomReader(t *testing.T) {
 			Escape:           'E',
 			TrimLeadingSpace: false,
 		},
-		r: bufio.NewReader(stringReader),
+		r: newBufferedLines(stringReader),
 	}
 
 	newReader := NewCustomReader(stringReader, expected.Parameters)
@@ -488,7 +490,7 @@ func TestReader_ReadAll(t *testing.T) {
 			r := newReader(tt)
 			out, err := r.ReadAll()
 			if tt.Error != nil {
-				if !reflect.DeepEqual(err, tt.Error) {
+				if !errors.Is(err, tt.Error) {
 					t.Fatalf("ReadAll error mismatch:"+
 						"\nexpected: %v (%#v)\nreceived: %v (%#v)",
 						tt.Error, tt.Error, err, err)
@@ -538,7 +540,7 @@ func TestReader_Read(t *testing.T) {
 			for line, err := r.Read(); err != io.EOF; line, err = r.Read() {
 				if tt.Error != nil {
 					if err != nil {
-						if !reflect.DeepEqual(err, tt.Error) {
+						if !errors.Is(err, tt.Error) {
 							t.Fatalf("Read error mismatch:"+
 								"\nexpected: %v (%#v)\nreceived: %v (%#v)",
 								tt.Error, tt.Error, err, err)
@@ -567,3 +569,519 @@ func TestReader_Read(t *testing.T) {
 		})
 	}
 }
+
+type parseErrorTest struct {
+	Name  string
+	Input string
+	Error *ParseError
+}
+
+var parseErrorTests = []parseErrorTest{{
+	Name:  "SingleLine",
+	Input: `"abc`,
+	Error: &ParseError{
+		StartLine: 1, Line: 1, Column: 5, Offset: 4, Err: ErrNoClosingRaw},
+}, {
+	Name:  "TrailingNewline",
+	Input: "a\n\"b\nc\n",
+	Error: &ParseError{
+		StartLine: 2, Line: 4, Column: 1, Offset: 7, Err: ErrNoClosingRaw},
+}, {
+	Name:  "ExtraneousQuote",
+	Input: "a\n\"word\n\"b",
+	Error: &ParseError{
+		StartLine: 2, Line: 3, Column: 3, Offset: 10, Err: ErrNoClosingRaw},
+}, {
+	Name:  "IndentedAfterComments",
+	Input: "# Comment\n\n  \"abc\n  def\n",
+	Error: &ParseError{
+		StartLine: 3, Line: 5, Column: 1, Offset: 24, Err: ErrNoClosingRaw},
+},
+}
+
+// Tests that Reader.ReadAll returns a ParseError with the expected position
+// for each test.
+func TestReader_ReadAll_ParseError(t *testing.T) {
+	for _, tt := range parseErrorTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(tt.Input))
+			r.FileName = "test.lsv"
+			_, err := r.ReadAll()
+
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("ReadAll did not return a ParseError: %+v", err)
+			}
+			if !errors.Is(err, tt.Error.Err) {
+				t.Errorf("ParseError does not wrap %v: %v", tt.Error.Err, err)
+			}
+
+			expected := *tt.Error
+			expected.File = r.FileName
+			if !reflect.DeepEqual(&expected, pe) {
+				t.Errorf("Unexpected ParseError.\nexpected: %+v\nreceived: %+v",
+					&expected, pe)
+			}
+		})
+	}
+}
+
+// Tests that ParseError.Error returns the expected string.
+func TestParseError_Error(t *testing.T) {
+	type test struct {
+		Name   string
+		Err    *ParseError
+		Output string
+	}
+
+	tests := []test{{
+		"SingleLine",
+		&ParseError{StartLine: 1, Line: 1, Column: 5, Err: ErrNoClosingRaw},
+		"parse error on line 1, column 5: raw literal not closed",
+	}, {
+		"MultiLine",
+		&ParseError{StartLine: 2, Line: 4, Column: 1, Err: ErrNoClosingRaw},
+		"value on line 2; parse error on line 4, column 1: " +
+			"raw literal not closed",
+	}, {
+		"File",
+		&ParseError{File: "a.lsv", StartLine: 1, Line: 1, Column: 5,
+			Err: ErrNoClosingRaw},
+		"a.lsv: parse error on line 1, column 5: raw literal not closed",
+	},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if s := tt.Err.Error(); s != tt.Output {
+				t.Errorf("Unexpected error string.\nexpected: %q\nreceived: %q",
+					tt.Output, s)
+			}
+		})
+	}
+}
+
+// Tests that Reader.FieldPos, Reader.ValueRange, and Reader.InputOffset return
+// the expected positions after each call to Reader.Read.
+func TestReader_ValueRange(t *testing.T) {
+	type position struct {
+		Start, End Position
+		Offset     int64
+	}
+	type test struct {
+		Name   string
+		Input  string
+		NoTrim bool
+		Output []position
+	}
+
+	tests := []test{{
+		Name:  "Simple",
+		Input: "a\nbc\ndef",
+		Output: []position{
+			{Position{1, 1, 0}, Position{1, 2, 1}, 2},
+			{Position{2, 1, 2}, Position{2, 3, 4}, 5},
+			{Position{3, 1, 5}, Position{3, 4, 8}, 8},
+		},
+	}, {
+		Name:  "IndentAndComments",
+		Input: "# Comment\n\n  abc  # Comment\n\tdef\r\n",
+		Output: []position{
+			{Position{3, 3, 13}, Position{3, 6, 16}, 28},
+			{Position{4, 2, 29}, Position{4, 5, 32}, 34},
+		},
+	}, {
+		Name:   "NoTrim",
+		Input:  "  abc\n",
+		NoTrim: true,
+		Output: []position{
+			{Position{1, 1, 0}, Position{1, 6, 5}, 6},
+		},
+	}, {
+		Name:  "Raw",
+		Input: "a\n  \" b \" # Comment\nc",
+		Output: []position{
+			{Position{1, 1, 0}, Position{1, 2, 1}, 2},
+			{Position{2, 3, 4}, Position{2, 8, 9}, 20},
+			{Position{3, 1, 20}, Position{3, 2, 21}, 21},
+		},
+	}, {
+		Name:  "MultiLineRaw",
+		Input: "\"two\nline\"\n \"three\n\nlines\"  \nx",
+		Output: []position{
+			{Position{1, 1, 0}, Position{2, 6, 10}, 11},
+			{Position{3, 2, 12}, Position{5, 7, 26}, 29},
+			{Position{6, 1, 29}, Position{6, 2, 30}, 30},
+		},
+	}, {
+		Name:  "NonASCII",
+		Input: "€\n\"λθ\"",
+		Output: []position{
+			{Position{1, 1, 0}, Position{1, 4, 3}, 4},
+			{Position{2, 1, 4}, Position{2, 7, 10}, 10},
+		},
+	},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(tt.Input))
+			r.TrimLeadingSpace = !tt.NoTrim
+
+			if line, column := r.FieldPos(); line != 0 || column != 0 {
+				t.Errorf("Unexpected position before first read: %d:%d",
+					line, column)
+			}
+
+			for i, expected := range tt.Output {
+				if _, err := r.Read(); err != nil {
+					t.Fatalf("Failed to read value %d: %+v", i, err)
+				}
+
+				start, end := r.ValueRange()
+				if start != expected.Start || end != expected.End {
+					t.Errorf("Unexpected range for value %d."+
+						"\nexpected: %+v to %+v\nreceived: %+v to %+v",
+						i, expected.Start, expected.End, start, end)
+				}
+
+				line, column := r.FieldPos()
+				if line != start.Line || column != start.Column {
+					t.Errorf("FieldPos does not match start of value %d."+
+						"\nexpected: %d:%d\nreceived: %d:%d",
+						i, start.Line, start.Column, line, column)
+				}
+
+				if offset := r.InputOffset(); offset != expected.Offset {
+					t.Errorf("Unexpected InputOffset for value %d."+
+						"\nexpected: %d\nreceived: %d",
+						i, expected.Offset, offset)
+				}
+			}
+
+			if _, err := r.Read(); err != io.EOF {
+				t.Errorf("Expected EOF after last value: %+v", err)
+			}
+		})
+	}
+}
+
+// Tests that Reader.ReadAllRecords returns the expected records for each test.
+func TestReader_ReadAllRecords(t *testing.T) {
+	type test struct {
+		Name   string
+		Input  string
+		Output []Record
+	}
+
+	tests := []test{{
+		Name:  "NoComments",
+		Input: "a\n\"b\"\n",
+		Output: []Record{{ValueComment{"a", ""}, false, nil},
+			{ValueComment{"b", ""}, true, nil}},
+	}, {
+		Name:  "InlineComments",
+		Input: "a # Comment 1\n\"  b\"\t#Comment 2  \r\n\"c\nd\" # Comment 3",
+		Output: []Record{{ValueComment{"a", "Comment 1"}, false, nil},
+			{ValueComment{"  b", "Comment 2"}, true, nil},
+			{ValueComment{"c\nd", "Comment 3"}, true, nil}},
+	}, {
+		Name: "BlockComments",
+		Input: "# Comment 1\n  # Comment 2\n#\na\n" +
+			"# Comment 3\n\"b\" # Comment 4",
+		Output: []Record{
+			{ValueComment{"a", ""}, false,
+				[]string{"Comment 1", "Comment 2", ""}},
+			{ValueComment{"b", "Comment 4"}, true, []string{"Comment 3"}}},
+	}, {
+		Name:  "DetachedBlockComments",
+		Input: "# Comment 1\n\n# Comment 2\na\n# Comment 3\n\nb\n# Comment 4\n",
+		Output: []Record{
+			{ValueComment{"a", ""}, false, []string{"Comment 2"}},
+			{ValueComment{"b", ""}, false, nil}},
+	}, {
+		Name:  "EscapedComment",
+		Input: "\\# Not a comment # Comment",
+		Output: []Record{
+			{ValueComment{"# Not a comment", "Comment"}, false, nil}},
+	}, {
+		Name:   "OnlyComments",
+		Input:  "# Comment 1\n# Comment 2\n",
+		Output: nil,
+	},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(tt.Input))
+			out, err := r.ReadAllRecords()
+			if err != nil {
+				t.Fatalf("Unexpected ReadAllRecords error: %+v", err)
+			}
+
+			if !reflect.DeepEqual(out, tt.Output) {
+				t.Errorf("ReadAllRecords unexpected output:"+
+					"\nexpected: %+v\nreceived: %+v", tt.Output, out)
+			}
+		})
+	}
+}
+
+// Tests that Reader.ReadRecord returns the same values as Reader.Read for each
+// test.
+func TestReader_ReadRecord(t *testing.T) {
+	for _, tt := range readTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(tt.Input))
+			r.TrimLeadingSpace = !tt.NoTrim
+			if tt.Comment != 0 {
+				r.Comment = tt.Comment
+			}
+			if tt.Raw != 0 {
+				r.Raw = tt.Raw
+			}
+			if tt.Escape != 0 {
+				r.Escape = tt.Escape
+			}
+
+			var out []string
+			for {
+				rec, err := r.ReadRecord()
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					if tt.Error == nil || !errors.Is(err, tt.Error) {
+						t.Fatalf("Unexpected ReadRecord error: %+v", err)
+					}
+					return
+				}
+				out = append(out, rec.Value)
+			}
+
+			if tt.Error != nil {
+				t.Fatalf("ReadRecord failed to error. Expected error: %v",
+					tt.Error)
+			}
+			if !reflect.DeepEqual(out, tt.Output) {
+				t.Errorf("ReadRecord unexpected output:"+
+					"\nexpected: %q\nreceived: %q", tt.Output, out)
+			}
+		})
+	}
+}
+
+// Tests that Reader.ReadBytes returns the same values as Reader.Read for each
+// test, with and without ReuseValue.
+func TestReader_ReadBytes(t *testing.T) {
+	for _, reuse := range []bool{false, true} {
+		for _, tt := range readTests {
+			t.Run(tt.Name, func(t *testing.T) {
+				r := NewReader(strings.NewReader(tt.Input))
+				r.TrimLeadingSpace = !tt.NoTrim
+				r.ReuseValue = reuse
+				if tt.Comment != 0 {
+					r.Comment = tt.Comment
+				}
+				if tt.Raw != 0 {
+					r.Raw = tt.Raw
+				}
+				if tt.Escape != 0 {
+					r.Escape = tt.Escape
+				}
+
+				var out []string
+				for {
+					value, err := r.ReadBytes()
+					if err == io.EOF {
+						break
+					} else if err != nil {
+						if tt.Error == nil || !errors.Is(err, tt.Error) {
+							t.Fatalf("Unexpected ReadBytes error: %+v", err)
+						}
+						return
+					}
+					out = append(out, string(value))
+				}
+
+				if tt.Error != nil {
+					t.Fatalf("ReadBytes failed to error. Expected error: %v",
+						tt.Error)
+				}
+				if !reflect.DeepEqual(out, tt.Output) {
+					t.Errorf("ReadBytes unexpected output:"+
+						"\nexpected: %q\nreceived: %q", tt.Output, out)
+				}
+			})
+		}
+	}
+}
+
+// Tests that Reader.ReadBytes returns the same values and errors as
+// Reader.ReadAll for every combination of special characters in both the
+// lenient and strict grammar.
+func TestReader_ReadBytes_Parity(t *testing.T) {
+	alphabet := []string{"a", " ", "\n", "\"", "\\", "#", "[", "x", "é"}
+	inputs := []string{""}
+	for n := 1; n <= 4; n++ {
+		inputs = append(inputs, combinations(alphabet, n)...)
+	}
+
+	params := []Parameters{DefaultParameters(), strictParameters(),
+		sequenceParameters(false), sequenceParameters(true),
+		sectionParameters(false), sectionParameters(true)}
+	for _, p := range params {
+		for _, input := range inputs {
+			expected, expectedErr := NewCustomReader(
+				strings.NewReader(input), p).ReadAll()
+
+			r := NewCustomReader(strings.NewReader(input), p)
+			r.ReuseValue = true
+			var values []string
+			var err error
+			for {
+				var value []byte
+				if value, err = r.ReadBytes(); err != nil {
+					break
+				}
+				values = append(values, string(value))
+			}
+			if err == io.EOF {
+				err = nil
+			} else {
+				values = nil
+			}
+
+			if !reflect.DeepEqual(expected, values) ||
+				!reflect.DeepEqual(expectedErr, err) {
+				t.Errorf("Different results for %q (%+v)."+
+					"\nReadAll:   %q, %v\nReadBytes: %q, %v",
+					input, p, expected, expectedErr, values, err)
+			}
+		}
+	}
+}
+
+// Tests that Reader.ReadBytes only returns slices that share memory if
+// ReuseValue is set.
+func TestReader_ReadBytes_ReuseValue(t *testing.T) {
+	for _, reuse := range []bool{false, true} {
+		r := NewReader(strings.NewReader("abc\n\"def\nghi\"\n"))
+		r.ReuseValue = reuse
+
+		first, err := r.ReadBytes()
+		if err != nil {
+			t.Fatalf("Failed to read first value: %+v", err)
+		}
+		second, err := r.ReadBytes()
+		if err != nil {
+			t.Fatalf("Failed to read second value: %+v", err)
+		}
+
+		if shared := &first[0] == &second[0]; shared != reuse {
+			t.Errorf("Unexpected memory sharing with ReuseValue %t."+
+				"\nexpected: %t\nreceived: %t", reuse, reuse, shared)
+		}
+		if !reuse && string(first) != "abc" {
+			t.Errorf("First value changed.\nexpected: %q\nreceived: %q",
+				"abc", first)
+		}
+	}
+}
+
+// Tests that reading all values with Reader.ReadBytes and ReuseValue through
+// NewCustomReader makes the same number of allocations for a short and a long
+// input, for every kind of value in both the lenient and strict grammar, so
+// that no values or chunks of the input are allocated.
+func TestReader_ReadBytes_Allocations(t *testing.T) {
+	block := "# Comment\nvalue # Inline\n  value \\# two\n\n" +
+		"\"quoted # value\"\n\"multi\nline\"\n"
+
+	for _, p := range []Parameters{DefaultParameters(), strictParameters()} {
+		readAll := func(input string) float64 {
+			return testing.AllocsPerRun(3, func() {
+				r := NewCustomReader(strings.NewReader(input), p)
+				r.ReuseValue = true
+				for {
+					if _, err := r.ReadBytes(); err == io.EOF {
+						break
+					} else if err != nil {
+						t.Fatalf("Failed to read: %+v", err)
+					}
+				}
+			})
+		}
+
+		short, long := readAll(block), readAll(strings.Repeat(block, 20000))
+		if short != long {
+			t.Errorf("Allocations depend on the input size (strict %t)."+
+				"\nshort input: %.0f\nlong input:  %.0f", p.Strict, short, long)
+		}
+	}
+}
+
+// Tests that the values, comments, keys, and sections returned by the string
+// APIs stay the same after more of the input is read into the reused buffer.
+func TestReader_KeptStrings(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&b, "[s%d]\n# Block %d\nk%d = value %d # Inline %d\n"+
+			"k\\#%d = \"multi\n%d\"\n", i, i, i, i, i, i, i)
+	}
+	input := b.String()
+	p := sectionParameters(false)
+
+	for _, r := range []io.Reader{strings.NewReader(input),
+		iotest.HalfReader(strings.NewReader(input))} {
+		lr := NewCustomReader(r, p)
+		lr.kv = true
+		var records []Record
+		var keys, sections []string
+		for {
+			rec, err := lr.ReadRecord()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("Failed to read: %+v", err)
+			}
+			records = append(records, rec)
+			keys = append(keys, lr.key)
+			sections = append(sections, lr.Section())
+		}
+
+		for i, rec := range records {
+			n := i / 2
+			expected := Record{ValueComment{fmt.Sprintf("value %d", n),
+				fmt.Sprintf("Inline %d", n)}, false,
+				[]string{fmt.Sprintf("Block %d", n)}}
+			expectedKey := fmt.Sprintf("k%d", n)
+			if i%2 == 1 {
+				expected = Record{ValueComment{
+					fmt.Sprintf("multi\n%d", n), ""}, true, nil}
+				expectedKey = fmt.Sprintf("k#%d", n)
+			}
+			if !reflect.DeepEqual(expected, rec) ||
+				keys[i] != expectedKey ||
+				sections[i] != fmt.Sprintf("s%d", n) {
+				t.Fatalf("Record %d changed after reading on."+
+					"\nexpected: %q %q %+v\nreceived: %q %q %+v",
+					i, expectedKey, fmt.Sprintf("s%d", n), expected,
+					keys[i], sections[i], rec)
+			}
+		}
+	}
+}
+
+// Tests that Reader.Read returns an error from the underlying reader inside a
+// raw string literal instead of ErrNoClosingRaw.
+func TestReader_Read_ReaderError(t *testing.T) {
+	readErr := errors.New("read error")
+	for _, p := range []Parameters{DefaultParameters(), strictParameters()} {
+		r := NewCustomReader(io.MultiReader(
+			strings.NewReader("\"abc\n"), iotest.ErrReader(readErr)), p)
+
+		if _, err := r.Read(); err != readErr {
+			t.Errorf("Unexpected error (strict %t)."+
+				"\nexpected: %v\nreceived: %v", p.Strict, readErr, err)
+		}
+	}
+}
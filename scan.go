@@ -0,0 +1,106 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// errNeedMore is returned by bytesLines when the buffer ends before the end of
+// the line and more data must be read.
+var errNeedMore = errors.New("need more data")
+
+// ScanValues is a split function for a [bufio.Scanner] that returns each value
+// of an LSV read with the default Parameters. It is equivalent to the function
+// returned by [ScanValuesParams] with [DefaultParameters].
+func ScanValues(data []byte, atEOF bool) (int, []byte, error) {
+	return scanValues(DefaultParameters(), data, atEOF)
+}
+
+// ScanValuesParams returns a split function for a [bufio.Scanner] that returns
+// each value of an LSV read using the Parameters. The values are the same as
+// those returned by [Reader.Read].
+//
+// A raw string literal that spans several lines is returned as a single token,
+// even if it does not fit in the data read so far; the split function requests
+// more data until the literal is closed, so each value must fit in the buffer
+// of the Scanner. Blank and comment lines between values are skipped as they
+// are read. If the input ends inside a raw string literal, the split function
+// returns [ErrNoClosingRaw]. Since the split function does not know the
+// position of the data in the input, it returns the sentinel errors of the
+// Reader and not a ParseError.
+//
+// If the Parameters cannot be verified, the split function returns
+// ErrInvalidParams.
+func ScanValuesParams(p Parameters) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		return scanValues(p, data, atEOF)
+	}
+}
+
+// scanValues is the internal helper function for ScanValuesParams.
+func scanValues(p Parameters, data []byte, atEOF bool) (int, []byte, error) {
+	if !p.Verify() {
+		return 0, nil, ErrInvalidParams
+	}
+
+	r := &Reader{Parameters: p, r: &bytesLines{data, atEOF}}
+	rec, inBuf, err := r.scanRecord(false)
+	var pe *ParseError
+	switch {
+	case err == errNeedMore:
+		// Skip the complete lines read so far if they are only blank and
+		// comment lines, so that they do not have to fit in the buffer
+		n, token, err := scanValues(p, data[:r.offset], true)
+		if err == nil && token == nil {
+			return n, nil, nil
+		}
+		return 0, nil, nil
+	case err == io.EOF:
+		// Only blank and comment lines are left
+		return len(data), nil, nil
+	case errors.As(err, &pe):
+		return 0, nil, pe.Err
+	case err != nil:
+		return 0, nil, err
+	case inBuf:
+		return int(r.offset), r.buf, nil
+	}
+	return int(r.offset), []byte(rec.Value), nil
+}
+
+// bytesLines reads the complete lines at the start of the data passed to a
+// split function. A line without a line ending is only complete at EOF.
+type bytesLines struct {
+	data  []byte
+	atEOF bool
+}
+
+// temporary returns false, since each line is copied from the data.
+func (bl *bytesLines) temporary() bool { return false }
+
+// readLine returns the next complete line or errNeedMore if the data ends
+// before the line does.
+func (bl *bytesLines) readLine() (string, error) {
+	i := bytes.IndexByte(bl.data, '\n') + 1
+	if i == 0 {
+		if !bl.atEOF {
+			return "", errNeedMore
+		} else if len(bl.data) == 0 {
+			return "", io.EOF
+		}
+		i = len(bl.data)
+	}
+
+	line := string(bl.data[:i])
+	bl.data = bl.data[i:]
+	return line, nil
+}
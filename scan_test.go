@@ -0,0 +1,128 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// scanAll returns every value scanned from the reader with the split function.
+// The scanner starts with a buffer of one byte, so that values straddle every
+// possible buffer boundary.
+func scanAll(r io.Reader, split bufio.SplitFunc) ([]string, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 1), bufio.MaxScanTokenSize)
+	s.Split(split)
+
+	var values []string
+	for s.Scan() {
+		values = append(values, s.Text())
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// Tests that ScanValues returns the expected values for each test.
+func TestScanValues(t *testing.T) {
+	for _, tt := range readTests {
+		if tt.Comment != 0 || tt.Raw != 0 || tt.Escape != 0 || tt.NoTrim {
+			continue
+		}
+		t.Run(tt.Name, func(t *testing.T) {
+			values, err := scanAll(strings.NewReader(tt.Input), ScanValues)
+			if tt.Error != nil {
+				if !errors.Is(err, tt.Error) {
+					t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+						tt.Error, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("Failed to scan: %+v", err)
+			}
+
+			if !reflect.DeepEqual(tt.Output, values) {
+				t.Errorf("Unexpected values.\nexpected: %q\nreceived: %q",
+					tt.Output, values)
+			}
+		})
+	}
+}
+
+// Tests that ScanValuesParams returns the same values as Reader.ReadAll and
+// the sentinel error of any ParseError for every combination of special
+// characters in the lenient and strict grammar.
+func TestScanValuesParams_Parity(t *testing.T) {
+	alphabet := []string{"a", " ", "\n", "\r\n", "\"", "\\", "#", "[", "é"}
+	inputs := []string{""}
+	for n := 1; n <= 4; n++ {
+		inputs = append(inputs, combinations(alphabet, n)...)
+	}
+
+	params := []Parameters{DefaultParameters(), strictParameters(),
+		sequenceParameters(false), sequenceParameters(true),
+		sectionParameters(false), sectionParameters(true)}
+	for _, p := range params {
+		for _, input := range inputs {
+			expected, expectedErr := NewCustomReader(
+				strings.NewReader(input), p).ReadAll()
+			var pe *ParseError
+			if errors.As(expectedErr, &pe) {
+				expectedErr = pe.Err
+			}
+
+			values, err := scanAll(iotest.HalfReader(
+				strings.NewReader(input)), ScanValuesParams(p))
+			if !reflect.DeepEqual(expected, values) || expectedErr != err {
+				t.Errorf("Different results for %q (%+v)."+
+					"\nReadAll:     %q, %v\nScanValues:  %q, %v",
+					input, p, expected, expectedErr, values, err)
+			}
+		}
+	}
+}
+
+// Tests that a raw string literal longer than the data read so far is
+// returned as one token and that an unclosed literal is an error at EOF.
+func TestScanValues_Raw(t *testing.T) {
+	long := strings.Repeat("x # y\n", 1000)
+	input := "a\n\"" + long + "\" # c\n\n# d\n\"b\"\n"
+
+	values, err := scanAll(strings.NewReader(input), ScanValues)
+	if err != nil {
+		t.Fatalf("Failed to scan: %+v", err)
+	}
+	if expected := []string{"a", long, "b"}; !reflect.DeepEqual(
+		expected, values) {
+		t.Errorf("Unexpected values.\nexpected: %d values\nreceived: %d values",
+			len(expected), len(values))
+	}
+
+	_, err = scanAll(strings.NewReader("a\n\""+long), ScanValues)
+	if !errors.Is(err, ErrNoClosingRaw) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrNoClosingRaw, err)
+	}
+}
+
+// Tests that the function returned by ScanValuesParams returns
+// ErrInvalidParams for invalid Parameters.
+func TestScanValuesParams_InvalidParams(t *testing.T) {
+	_, err := scanAll(strings.NewReader("a\n"), ScanValuesParams(Parameters{}))
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrInvalidParams, err)
+	}
+}
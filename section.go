@@ -0,0 +1,166 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Section header characters.
+const (
+	sectionOpen  = '['
+	sectionClose = ']'
+)
+
+// ErrSectionNotClosed is returned when a section header does not end in the
+// closing section character.
+var ErrSectionNotClosed = errors.New("section header not closed")
+
+// isSectionLine determines if the line, with its leading whitespace already
+// handled, is a section header.
+func (p Parameters) isSectionLine(line string) bool {
+	c, _ := utf8.DecodeRuneInString(line)
+	return p.Sections && c == sectionOpen
+}
+
+// parseSection parses the section header on the line and returns the section
+// name and the inline comment. On a syntax error, it returns the index in the
+// line where the error occurred.
+func (p Parameters) parseSection(line string) (
+	name, comment string, errAt int, err error) {
+	header, comment, found := p.cutComment(line, false)
+	header = strings.TrimRightFunc(header, unicode.IsSpace)
+
+	c, size := utf8.DecodeLastRuneInString(header)
+	if c != sectionClose || len(header) == size {
+		return "", "", len(header), ErrSectionNotClosed
+	}
+
+	name = strings.TrimSpace(header[size : len(header)-size])
+	if found {
+		comment = strings.TrimSpace(comment)
+	}
+	return name, comment, 0, nil
+}
+
+// readSection sets the current section to the one in the header on the line,
+// which starts at pos.
+func (r *Reader) readSection(line string, pos Position) error {
+	name, _, errAt, err := r.parseSection(line)
+	if err != nil {
+		return r.newParseError(pos.Line, pos.add(errAt), err)
+	}
+	r.section = r.keep(name)
+	if r.onSection != nil {
+		r.onSection(r.section)
+	}
+	return nil
+}
+
+// Section returns the name of the current section, which is the section of the
+// most recently read value. It is only set if Sections is enabled in the
+// Parameters. Values before the first section header are in the section with
+// an empty name.
+func (r *Reader) Section() string {
+	return r.section
+}
+
+// ReadSections reads all the remaining values from r and groups them by
+// section. It returns the values of each section and the names of the sections
+// in the order in which they first appear. Values before the first section
+// header are in the section with an empty name, which is only included if it
+// has values. A section that appears more than once is treated as a single
+// section, and a section without values is included with no values.
+//
+// If Sections is not enabled in the Parameters, all values are in the section
+// with an empty name.
+func (r *Reader) ReadSections() (map[string][]string, []string, error) {
+	if !r.Verify() {
+		return nil, nil, ErrInvalidParams
+	}
+
+	sections := make(map[string][]string)
+	var order []string
+	add := func(name string) {
+		if _, exists := sections[name]; !exists {
+			sections[name] = nil
+			order = append(order, name)
+		}
+	}
+
+	r.onSection = add
+	defer func() { r.onSection = nil }()
+
+	for {
+		rec, err := r.readRecord()
+		if err == io.EOF {
+			return sections, order, nil
+		} else if err != nil {
+			return nil, nil, err
+		}
+
+		add(r.section)
+		sections[r.section] = append(sections[r.section], rec.Value)
+	}
+}
+
+// WriteSection writes a section header that starts a section with the name.
+// Every value written after it is part of the section until the next header.
+// It returns ErrUnrepresentable if the name contains a newline or the Comment
+// character or has leading or trailing whitespace.
+//
+// Writes are buffered, so [Writer.Flush] must eventually be called to ensure
+// that the header is written to the underlying [io.Writer].
+func (w *Writer) WriteSection(name string) error {
+	if !w.Verify() {
+		return ErrInvalidParams
+	}
+	return w.writeSection(name, "")
+}
+
+// writeSection writes a section header with an optional inline comment.
+func (w *Writer) writeSection(name, comment string) error {
+	if strings.ContainsAny(name, "\r\n") ||
+		strings.ContainsRune(name, w.Comment) ||
+		strings.TrimSpace(name) != name {
+		return ErrUnrepresentable
+	} else if strings.IndexByte(comment, '\n') > -1 {
+		return ErrCommentNewline
+	}
+
+	err := w.writeAligned()
+	if err != nil {
+		return err
+	}
+	header := string(sectionOpen) + name + string(sectionClose)
+	return w.writeLine(header, "", comment)
+}
+
+// escapeSection escapes the opening section character at the start of an
+// encoded unquoted value so that it is not read as a section header.
+func (p Parameters) escapeSection(encoded string) string {
+	if c, _ := utf8.DecodeRuneInString(encoded); c == sectionOpen &&
+		p.Sections {
+		return string(p.Escape) + encoded
+	}
+	return encoded
+}
+
+// unescapeSection removes the Escape character before an opening section
+// character at the start of an unquoted value in the lenient grammar.
+func (p Parameters) unescapeSection(value string) string {
+	esc := string(p.Escape)
+	if p.Sections && strings.HasPrefix(value, esc+string(sectionOpen)) {
+		return value[len(esc):]
+	}
+	return value
+}
@@ -0,0 +1,363 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sectionParameters returns the default Parameters with Sections set.
+func sectionParameters(strict bool) Parameters {
+	p := DefaultParameters()
+	p.Sections = true
+	p.Strict = strict
+	return p
+}
+
+// Tests that Reader.Section returns the section of the most recently read
+// value in both the lenient and strict grammar.
+func TestReader_Section(t *testing.T) {
+	src := "a\n[ one ] # c\nb\n\n  [two]\n[three]\nc\n\"[d]\"\n\\[e]\n"
+	expected := []struct{ Value, Section string }{
+		{"a", ""}, {"b", "one"}, {"c", "three"}, {"[d]", "three"},
+		{"[e]", "three"},
+	}
+
+	for _, strict := range []bool{false, true} {
+		r := NewCustomReader(strings.NewReader(src), sectionParameters(strict))
+		for _, e := range expected {
+			value, err := r.Read()
+			if err != nil {
+				t.Fatalf("Failed to read %q (strict %t): %+v", e.Value, strict,
+					err)
+			}
+			if value != e.Value || r.Section() != e.Section {
+				t.Errorf("Unexpected value and section (strict %t)."+
+					"\nexpected: %q in %q\nreceived: %q in %q",
+					strict, e.Value, e.Section, value, r.Section())
+			}
+		}
+		if _, err := r.Read(); err != io.EOF {
+			t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+				io.EOF, err)
+		}
+	}
+}
+
+// Tests that section headers are read as values if Sections is not set.
+func TestReader_Section_Disabled(t *testing.T) {
+	values, err := NewReader(strings.NewReader("[a]\n\\[b]\n")).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read: %+v", err)
+	}
+	if expected := []string{"[a]", "\\[b]"}; !reflect.DeepEqual(
+		expected, values) {
+		t.Errorf("Unexpected values.\nexpected: %q\nreceived: %q",
+			expected, values)
+	}
+}
+
+// Tests that Reader.ReadSections groups the values by section in the order the
+// sections first appear.
+func TestReader_ReadSections(t *testing.T) {
+	type test struct {
+		Name     string
+		Input    string
+		Sections map[string][]string
+		Order    []string
+	}
+
+	tests := []test{
+		{"Empty", "", map[string][]string{}, nil},
+		{"NoHeaders", "a\nb\n", map[string][]string{"": {"a", "b"}},
+			[]string{""}},
+		{"Sections", "a\n[x]\nb\nc\n[y]\nd\n",
+			map[string][]string{"": {"a"}, "x": {"b", "c"}, "y": {"d"}},
+			[]string{"", "x", "y"}},
+		{"EmptySections", "[x]\n[y]\n# c\n[z]\na\n",
+			map[string][]string{"x": nil, "y": nil, "z": {"a"}},
+			[]string{"x", "y", "z"}},
+		{"Repeated", "[x]\na\n[y]\nb\n[x]\nc\n",
+			map[string][]string{"x": {"a", "c"}, "y": {"b"}},
+			[]string{"x", "y"}},
+		{"EmptyName", "[x]\na\n[]\nb\n",
+			map[string][]string{"x": {"a"}, "": {"b"}},
+			[]string{"x", ""}},
+	}
+
+	for _, strict := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(tt.Name, func(t *testing.T) {
+				r := NewCustomReader(
+					strings.NewReader(tt.Input), sectionParameters(strict))
+				sections, order, err := r.ReadSections()
+				if err != nil {
+					t.Fatalf("Failed to read sections: %+v", err)
+				}
+				if !reflect.DeepEqual(tt.Sections, sections) {
+					t.Errorf("Unexpected sections.\nexpected: %q\nreceived: %q",
+						tt.Sections, sections)
+				}
+				if !reflect.DeepEqual(tt.Order, order) {
+					t.Errorf("Unexpected order.\nexpected: %q\nreceived: %q",
+						tt.Order, order)
+				}
+			})
+		}
+	}
+}
+
+// Tests that Reader.ReadSections returns all values in the section with an
+// empty name if Sections is not set.
+func TestReader_ReadSections_Disabled(t *testing.T) {
+	sections, order, err :=
+		NewReader(strings.NewReader("[a]\nb\n")).ReadSections()
+	if err != nil {
+		t.Fatalf("Failed to read sections: %+v", err)
+	}
+	expected := map[string][]string{"": {"[a]", "b"}}
+	if !reflect.DeepEqual(expected, sections) {
+		t.Errorf("Unexpected sections.\nexpected: %q\nreceived: %q",
+			expected, sections)
+	}
+	if !reflect.DeepEqual([]string{""}, order) {
+		t.Errorf("Unexpected order: %q", order)
+	}
+}
+
+// Tests that a section header that is not closed is returned as a ParseError
+// by Reader and SplitParams in both the lenient and strict grammar.
+func TestReader_ReadAll_ErrSectionNotClosed(t *testing.T) {
+	type test struct {
+		Name   string
+		Input  string
+		Line   int
+		Column int
+	}
+
+	tests := []test{
+		{"NoClose", "a\n[b\n", 2, 3},
+		{"Comment", "[a]\n  [b] c # d]\n", 2, 8},
+		{"OnlyOpen", "[\n", 1, 2},
+	}
+
+	for _, strict := range []bool{false, true} {
+		p := sectionParameters(strict)
+		for _, tt := range tests {
+			t.Run(tt.Name, func(t *testing.T) {
+				_, err := NewCustomReader(
+					strings.NewReader(tt.Input), p).ReadAll()
+				checkSectionError(t, tt.Line, tt.Column, strict, err)
+
+				_, err = SplitParams(tt.Input, p)
+				checkSectionError(t, tt.Line, tt.Column, strict, err)
+			})
+		}
+	}
+}
+
+// checkSectionError checks that err is a ParseError for ErrSectionNotClosed at
+// the line and column.
+func checkSectionError(
+	t *testing.T, line, column int, strict bool, err error) {
+	var pe *ParseError
+	if !errors.As(err, &pe) || !errors.Is(err, ErrSectionNotClosed) {
+		t.Errorf("Unexpected error (strict %t).\nexpected: %v\nreceived: %v",
+			strict, ErrSectionNotClosed, err)
+	} else if pe.Line != line || pe.Column != column {
+		t.Errorf("Unexpected position (strict %t)."+
+			"\nexpected: %d:%d\nreceived: %d:%d",
+			strict, line, column, pe.Line, pe.Column)
+	}
+}
+
+// Tests that SplitParams skips section headers.
+func TestSplitParams_Sections(t *testing.T) {
+	src := "a\n[x] # c\n\t[y]\n\"[b]\"\n\\[c]\n"
+	expected := []string{"a", "[b]", "[c]"}
+	for _, strict := range []bool{false, true} {
+		values, err := SplitParams(src, sectionParameters(strict))
+		if err != nil {
+			t.Fatalf("Failed to split (strict %t): %+v", strict, err)
+		}
+		if !reflect.DeepEqual(expected, values) {
+			t.Errorf("Unexpected values (strict %t)."+
+				"\nexpected: %q\nreceived: %q", strict, expected, values)
+		}
+	}
+}
+
+// Tests that Writer.WriteSection writes a section header between values.
+func TestWriter_WriteSection(t *testing.T) {
+	var buff bytes.Buffer
+	w := NewCustomWriter(&buff, sectionParameters(false))
+	w.AlignComments = true
+	for _, write := range []func() error{
+		func() error { return w.WriteComment("a", "c") },
+		func() error { return w.WriteSection("one two") },
+		func() error { return w.Write("[b]") },
+		func() error { return w.WriteComment("cc", "d") },
+		func() error { return w.WriteSection("") },
+	} {
+		if err := write(); err != nil {
+			t.Fatalf("Failed to write: %+v", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		t.Fatalf("Failed to flush: %+v", err)
+	}
+
+	expected := "a\t# c\n[one two]\n\\[b]\ncc\t# d\n[]\n"
+	if buff.String() != expected {
+		t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+			expected, buff.String())
+	}
+}
+
+// Tests that Writer.WriteSection returns ErrUnrepresentable for names that
+// cannot be read back.
+func TestWriter_WriteSection_ErrUnrepresentable(t *testing.T) {
+	names := []string{"a\nb", "a\rb", "a # b", " a", "a\t"}
+	for _, name := range names {
+		w := NewCustomWriter(&bytes.Buffer{}, sectionParameters(false))
+		if err := w.WriteSection(name); !errors.Is(err, ErrUnrepresentable) {
+			t.Errorf("Unexpected error for %q.\nexpected: %v\nreceived: %v",
+				name, ErrUnrepresentable, err)
+		}
+	}
+
+	w := NewCustomWriter(&bytes.Buffer{}, Parameters{Comment: '['})
+	if err := w.WriteSection("a"); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrInvalidParams, err)
+	}
+}
+
+// Tests that values starting with section header characters written by a
+// Writer with Sections set are read back unchanged by Reader and SplitParams.
+func TestWriter_Sections_RoundTrip(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		p := sectionParameters(strict)
+		alphabet := []string{"a", " ", "[", "]", "\\", "#", "\""}
+		var values []string
+		for n := 1; n <= 4; n++ {
+			values = append(values, combinations(alphabet, n)...)
+		}
+
+		var buff bytes.Buffer
+		w := NewCustomWriter(&buff, p)
+		var written []string
+		for _, value := range values {
+			err := w.Write(value)
+			if errors.Is(err, ErrUnrepresentable) && !strict {
+				continue
+			} else if err != nil {
+				t.Fatalf("Failed to write %q: %+v", value, err)
+			}
+			written = append(written, value)
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			t.Fatalf("Failed to flush: %+v", err)
+		}
+
+		read, err := NewCustomReader(
+			bytes.NewReader(buff.Bytes()), p).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to read (strict %t): %+v", strict, err)
+		}
+		if !reflect.DeepEqual(written, read) {
+			t.Errorf("Reader did not read back values (strict %t).", strict)
+		}
+
+		split, err := SplitParams(buff.String(), p)
+		if err != nil {
+			t.Fatalf("Failed to split (strict %t): %+v", strict, err)
+		}
+		if !reflect.DeepEqual(written, split) {
+			t.Errorf("SplitParams did not read back values (strict %t).",
+				strict)
+		}
+	}
+}
+
+// Tests that KVReader returns the section of each pair and only reports
+// duplicate keys within the same section.
+func TestKVReader_Sections(t *testing.T) {
+	p := sectionParameters(false)
+	src := "a = 1\n[x]\na = 2\nb = \\[3]\n[y]\na = 4\n"
+	r := NewCustomKVReader(strings.NewReader(src), p)
+
+	expected := []struct{ Key, Value, Section string }{
+		{"a", "1", ""}, {"a", "2", "x"}, {"b", "[3]", "x"}, {"a", "4", "y"},
+	}
+	for _, e := range expected {
+		kv, err := r.Read()
+		if err != nil {
+			t.Fatalf("Failed to read %q: %+v", e.Key, err)
+		}
+		if kv.Key != e.Key || kv.Value != e.Value || r.Section() != e.Section {
+			t.Errorf("Unexpected pair.\nexpected: %q = %q in %q"+
+				"\nreceived: %q = %q in %q",
+				e.Key, e.Value, e.Section, kv.Key, kv.Value, r.Section())
+		}
+	}
+
+	r = NewCustomKVReader(strings.NewReader("[x]\na = 1\n[y]\n[x]\na = 2\n"), p)
+	_, err := r.ReadAll()
+	if !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %v",
+			ErrDuplicateKey, err)
+	}
+}
+
+// Tests that a Document keeps section headers as SectionNodes and that Format
+// normalizes them.
+func TestDocument_Sections(t *testing.T) {
+	src := "a\n  [ x ]   # comment\nb\n"
+	p := sectionParameters(false)
+	d, err := ParseDocument(strings.NewReader(src), p)
+	if err != nil {
+		t.Fatalf("Failed to parse document: %+v", err)
+	}
+
+	n := d.Nodes[1]
+	if n.Kind != SectionNode || n.Value != "x" || n.Comment != "comment" {
+		t.Errorf("Unexpected node: %+v", n)
+	}
+	if !reflect.DeepEqual([]string{"a", "b"}, d.Values()) {
+		t.Errorf("Unexpected values: %q", d.Values())
+	}
+
+	n.Value = "y"
+	d.Insert(3, NewSectionNode("z"), NewValueNode("[c]", ""))
+	var buff bytes.Buffer
+	if _, err = d.WriteTo(&buff); err != nil {
+		t.Fatalf("Failed to write document: %+v", err)
+	}
+	expected := "a\n  [y]\t# comment\nb\n[z]\n\\[c]\n"
+	if buff.String() != expected {
+		t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+			expected, buff.String())
+	}
+
+	formatted, err := Format([]byte(src), p)
+	if err != nil {
+		t.Fatalf("Failed to format: %+v", err)
+	}
+	if expected = "a\n[x]\t# comment\nb\n"; string(formatted) != expected {
+		t.Errorf("Unexpected format.\nexpected: %q\nreceived: %q",
+			expected, formatted)
+	}
+}
@@ -8,6 +8,7 @@
 package lsv
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -37,7 +38,7 @@ func TestSplitParams(t *testing.T) {
 			p := newParameters(tt)
 			out, err := SplitParams(tt.Input, p)
 			if tt.Error != nil {
-				if !reflect.DeepEqual(err, tt.Error) {
+				if !errors.Is(err, tt.Error) {
 					t.Fatalf("SplitParams() error mismatch:"+
 						"\nexpected: %v (%#v)\nreceived: %v (%#v)",
 						tt.Error, tt.Error, err, err)
@@ -58,3 +59,22 @@ func TestSplitParams(t *testing.T) {
 		})
 	}
 }
+
+// Tests that SplitParams returns a ParseError with the expected position for
+// each test.
+func TestSplitParams_ParseError(t *testing.T) {
+	for _, tt := range parseErrorTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := SplitParams(tt.Input, DefaultParameters())
+
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("SplitParams did not return a ParseError: %+v", err)
+			}
+			if !reflect.DeepEqual(tt.Error, pe) {
+				t.Errorf("Unexpected ParseError.\nexpected: %+v\nreceived: %+v",
+					tt.Error, pe)
+			}
+		})
+	}
+}
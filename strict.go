@@ -0,0 +1,223 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// strictLine is a single line of a value decoded with the strict grammar.
+type strictLine struct {
+	value   string // Decoded text of the value on the line, if not copied
+	buf     []byte // The dst with the decoded text appended, if copied
+	copied  bool   // True if the line has escapes and its text is in buf
+	inRaw   bool   // True if the raw string literal continues on the next line
+	end     int    // Index after the last byte of the value in the line
+	comment string // Text after the Comment character
+	found   bool   // True if the line has a comment
+	errAt   int    // Index in the line where a syntax error occurred
+	err     error  // Syntax error, if any
+}
+
+// decodeStrictLine decodes a line of a value using the strict grammar. If inRaw
+// is true, the line is inside a raw string literal and the opening Raw
+// character, if on this line, has already been removed. Otherwise, it is an
+// unquoted value and its leading whitespace has already been handled.
+//
+// The text of a line without escapes is sliced from the line. Once an escape
+// sequence is decoded, the text is instead appended to dst and returned in buf.
+func (p Parameters) decodeStrictLine(
+	dst []byte, line string, inRaw bool) strictLine {
+	var l strictLine
+	start, i := 0, 0
+	text := func(end int) {
+		if l.copied {
+			l.buf = append(l.buf, line[start:end]...)
+		} else {
+			l.value = line[start:end]
+		}
+	}
+
+	for i < len(line) {
+		c, size := rune(line[i]), 1
+		if c >= utf8.RuneSelf {
+			c, size = utf8.DecodeRuneInString(line[i:])
+		}
+
+		if c == p.Escape {
+			next, n := utf8.DecodeRuneInString(line[i+size:])
+			decoded, ok := string(next), n > 0 && (next == p.Escape ||
+				next == p.Comment || next == p.Raw ||
+				(p.Sections && next == sectionOpen))
+			if !ok && inRaw && p.EscapeSequences {
+				decoded, n, ok = p.decodeSequence(line[i+size:])
+			}
+			if !ok {
+				return strictLine{errAt: i, err: ErrInvalidEscape}
+			}
+
+			if !l.copied {
+				l.buf, l.copied = dst, true
+			}
+			l.buf = append(l.buf, line[start:i]...)
+			l.buf = append(l.buf, decoded...)
+			i += size + n
+			start = i
+			continue
+		}
+
+		if inRaw && c == p.Raw {
+			// The raw string literal ends and only whitespace and a comment
+			// can follow it
+			text(i)
+			l.end = i + size
+			rest := line[l.end:]
+			trimmed := trimLeftSpace(rest)
+			if c, n := utf8.DecodeRuneInString(trimmed); c == p.Comment {
+				l.comment, l.found = trimmed[n:], true
+			} else if trimmed != "" {
+				l.errAt = l.end + len(rest) - len(trimmed)
+				l.err = ErrTextAfterRaw
+			}
+			return l
+		} else if !inRaw && c == p.Comment {
+			l.comment, l.found = line[i+size:], true
+			break
+		}
+
+		i += size
+	}
+
+	if inRaw {
+		text(i)
+		l.inRaw = true
+		return l
+	}
+
+	// Escape sequences never decode to whitespace, so the trailing whitespace
+	// of the decoded value is the same as that of the source
+	l.end = len(trimRightSpace(line[:i]))
+	text(l.end)
+	return l
+}
+
+// scanStrictRecord is the internal helper function for scanRecord in strict
+// mode. The rec holds the reused block comment.
+func (r *Reader) scanStrictRecord(
+	rec Record, comments bool) (Record, bool, error) {
+	var start Position
+	var hasKey, inBuf bool
+
+	for {
+		// Position of the start of the unprocessed part of the line
+		lineStart := r.pos()
+
+		line, err := r.readLine()
+		if err == io.EOF && rec.Quoted {
+			return Record{}, false,
+				r.newParseError(start.Line, r.pos(), ErrNoClosingRaw)
+		} else if err != nil {
+			return Record{}, false, err
+		}
+
+		if !rec.Quoted {
+			if r.TrimLeadingSpace {
+				n := len(line)
+				line = trimLeftSpace(line)
+				lineStart = lineStart.add(n - len(line))
+			}
+			start = lineStart
+
+			// A section header ends any block comment above it
+			if r.isSectionLine(line) {
+				if err = r.readSection(line, lineStart); err != nil {
+					return Record{}, false, err
+				}
+				rec.BlockComment = rec.BlockComment[:0]
+				continue
+			}
+
+			// In key/value mode, the key and Separator character come before
+			// the value on any line that is not blank or a comment line
+			if r.kv && r.isKeyLine(line) {
+				line, lineStart, err = r.readKey(line, lineStart)
+				if err != nil {
+					return Record{}, false, err
+				}
+				start, hasKey = lineStart, true
+			}
+
+			// Check if the value is a raw string literal
+			if c, size := utf8.DecodeRuneInString(line); c == r.Raw {
+				rec.Quoted = true
+				line = line[size:]
+				lineStart = lineStart.add(size)
+			}
+		}
+
+		l := r.decodeStrictLine(r.buf, line, rec.Quoted)
+		if l.err != nil {
+			return Record{}, false, r.newParseError(
+				start.Line, lineStart.add(l.errAt), l.err)
+		}
+
+		// The value is copied to buf if it has escapes or spans several lines
+		if l.copied {
+			r.buf, inBuf = l.buf, true
+		} else if l.inRaw || inBuf {
+			r.buf, inBuf = append(r.buf, l.value...), true
+		}
+
+		if l.inRaw {
+			continue
+		} else if !rec.Quoted && !inBuf && l.value == "" && !hasKey {
+			// Lines without a value are either comment lines, which are kept
+			// as part of the block comment, or blank lines
+			if l.found && r.readInclude(l.comment, start) {
+				return Record{}, false, errInclude
+			} else if !l.found {
+				rec.BlockComment = rec.BlockComment[:0]
+			} else if comments {
+				rec.BlockComment = append(
+					rec.BlockComment, r.keep(trimSpace(l.comment)))
+			}
+			continue
+		}
+
+		r.start, r.end = start, lineStart.add(l.end)
+		if !inBuf {
+			rec.Value = l.value
+		}
+		if l.found {
+			rec.Comment = trimSpace(l.comment)
+		}
+		return rec, inBuf, nil
+	}
+}
+
+// encodeStrictValue returns the value as it is written to the LSV using the
+// strict grammar. If quote is true, the value is always written as a raw
+// string literal. Every value can be written in strict mode.
+func (w *Writer) encodeStrictValue(value string, quote bool) string {
+	esc := string(w.Escape)
+	if !quote && !w.valueNeedsEscaping(value) &&
+		!(w.EscapeSequences && needsSequence(value)) {
+		comment := string(w.Comment)
+		return w.escapeSection(strings.NewReplacer(
+			esc, esc+esc, comment, esc+comment).Replace(value))
+	}
+
+	raw := string(w.Raw)
+	if w.EscapeSequences {
+		return raw + w.encodeSequences(value) + raw
+	}
+	return raw + strings.NewReplacer(
+		esc, esc+esc, raw, esc+raw).Replace(value) + raw
+}
@@ -0,0 +1,233 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// strictParameters returns the default Parameters in strict mode.
+func strictParameters() Parameters {
+	p := DefaultParameters()
+	p.Strict = true
+	return p
+}
+
+var strictTests = []struct {
+	Name   string
+	Input  string
+	Output []string
+	Error  *ParseError
+}{
+	{"Empty", "", nil, nil},
+	{"Plain", "a\n  b  \n\nc", []string{"a", "b", "c"}, nil},
+	{"Comments", "# a\nb # c\n#d\n", []string{"b"}, nil},
+	{"EscapedEscape", "a\\\\b\n\\\\\n", []string{"a\\b", "\\"}, nil},
+	{"EscapedComment", "a\\#b\n\\\\# c\n", []string{"a#b", "\\"}, nil},
+	{"EscapedRaw", "\\\"a\"\na\\\"b\n", []string{"\"a\"", "a\"b"}, nil},
+	{"UnescapedRaw", "a\"b\"\n", []string{"a\"b\""}, nil},
+	{"Raw", "\" a # b \"  # c\n\"\"\n", []string{" a # b ", ""}, nil},
+	{"RawEscapes", "\"a\\\"b\\\\\"\n\"\\#\"\n",
+		[]string{"a\"b\\", "#"}, nil},
+	{"RawMultiLine", "\"a\nb\\\"\n\"\n", []string{"a\nb\"\n"}, nil},
+	{"RawCRLF", "\"a\r\nb\"\r\nc\r\n", []string{"a\r\nb", "c"}, nil},
+	{"InvalidEscape", "a\nb\\c\n", nil,
+		&ParseError{StartLine: 2, Line: 2, Column: 2, Offset: 3,
+			Err: ErrInvalidEscape}},
+	{"TrailingEscape", "a\\\n", nil,
+		&ParseError{StartLine: 1, Line: 1, Column: 2, Offset: 1,
+			Err: ErrInvalidEscape}},
+	{"TrailingEscapeEOF", "  a\\", nil,
+		&ParseError{StartLine: 1, Line: 1, Column: 4, Offset: 3,
+			Err: ErrInvalidEscape}},
+	{"RawInvalidEscape", "\"a\n\\n\"\n", nil,
+		&ParseError{StartLine: 1, Line: 2, Column: 1, Offset: 3,
+			Err: ErrInvalidEscape}},
+	{"RawEscapedNewline", "\"a\\\nb\"\n", nil,
+		&ParseError{StartLine: 1, Line: 1, Column: 3, Offset: 2,
+			Err: ErrInvalidEscape}},
+	{"TextAfterRaw", "\"a\"b\"\n", nil,
+		&ParseError{StartLine: 1, Line: 1, Column: 4, Offset: 3,
+			Err: ErrTextAfterRaw}},
+	{"TextAfterMultiLineRaw", "\n\"a\nb\"  c # d\n", nil,
+		&ParseError{StartLine: 2, Line: 3, Column: 5, Offset: 8,
+			Err: ErrTextAfterRaw}},
+	{"NoClosingRaw", "a\n\"b\\\"\n", nil,
+		&ParseError{StartLine: 2, Line: 3, Column: 1, Offset: 7,
+			Err: ErrNoClosingRaw}},
+}
+
+// Tests that Reader.ReadAll in strict mode returns the expected values and
+// errors.
+func TestReader_ReadAll_Strict(t *testing.T) {
+	for _, tt := range strictTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			values, err := NewCustomReader(
+				strings.NewReader(tt.Input), strictParameters()).ReadAll()
+			checkStrictResult(t, tt.Output, tt.Error, values, err)
+		})
+	}
+}
+
+// Tests that SplitParams in strict mode returns the same values and errors as
+// Reader.ReadAll.
+func TestSplitParams_Strict(t *testing.T) {
+	for _, tt := range strictTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			values, err := SplitParams(tt.Input, strictParameters())
+			checkStrictResult(t, tt.Output, tt.Error, values, err)
+		})
+	}
+}
+
+// checkStrictResult checks that the values and error match the expected ones.
+func checkStrictResult(t *testing.T, expected []string,
+	expectedErr *ParseError, values []string, err error) {
+	if expectedErr != nil {
+		var pe *ParseError
+		if !errors.As(err, &pe) || !reflect.DeepEqual(expectedErr, pe) {
+			t.Errorf("Unexpected error.\nexpected: %+v\nreceived: %+v",
+				expectedErr, err)
+		}
+	} else if err != nil {
+		t.Errorf("Unexpected error: %+v", err)
+	} else if !reflect.DeepEqual(expected, values) {
+		t.Errorf("Unexpected values.\nexpected: %q\nreceived: %q",
+			expected, values)
+	}
+}
+
+// Tests that Reader.ReadRecord in strict mode returns the comments and
+// positions of each value.
+func TestReader_ReadRecord_Strict(t *testing.T) {
+	type result struct {
+		Record     Record
+		Start, End Position
+	}
+
+	src := "# Block\n  a\\# # Comment\n\"b\nc\"  #Inline\n"
+	expected := []result{{
+		Record{ValueComment{"a#", "Comment"}, false, []string{"Block"}},
+		Position{2, 3, 10}, Position{2, 6, 13},
+	}, {
+		Record{ValueComment{"b\nc", "Inline"}, true, nil},
+		Position{3, 1, 24}, Position{4, 3, 29},
+	}}
+
+	r := NewCustomReader(strings.NewReader(src), strictParameters())
+	var results []result
+	for {
+		rec, err := r.ReadRecord()
+		if err != nil {
+			break
+		}
+		start, end := r.ValueRange()
+		results = append(results, result{rec, start, end})
+	}
+
+	if !reflect.DeepEqual(expected, results) {
+		t.Errorf("Unexpected records.\nexpected: %+v\nreceived: %+v",
+			expected, results)
+	}
+}
+
+// Tests that every combination of special characters written by a Writer in
+// strict mode is read back unchanged by Reader and SplitParams in strict mode.
+func TestWriter_Strict_RoundTrip(t *testing.T) {
+	params := []Parameters{strictParameters(),
+		{Comment: ';', Raw: '\'', Escape: '/', Strict: true}}
+	for _, p := range params {
+		alphabet := []string{"a", " ", "\n", "\r", string(p.Comment),
+			string(p.Raw), string(p.Escape)}
+		values := []string{""}
+		for n := 1; n <= 4; n++ {
+			values = append(values, combinations(alphabet, n)...)
+		}
+
+		var buff bytes.Buffer
+		w := NewCustomWriter(&buff, p)
+		for _, value := range values {
+			rec := Record{ValueComment: ValueComment{value, "c"}}
+			rec.Quoted = value == ""
+			if err := w.WriteRecord(rec); err != nil {
+				t.Fatalf("Failed to write %q: %+v", value, err)
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			t.Fatalf("Failed to flush: %+v", err)
+		}
+
+		read, err := NewCustomReader(
+			bytes.NewReader(buff.Bytes()), p).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to read: %+v", err)
+		}
+		if !reflect.DeepEqual(values, read) {
+			t.Errorf("Reader did not read back values for %+v.", p)
+		}
+
+		split, err := SplitParams(buff.String(), p)
+		if err != nil {
+			t.Fatalf("Failed to split: %+v", err)
+		}
+		if !reflect.DeepEqual(values, split) {
+			t.Errorf("SplitParams did not read back values for %+v.", p)
+		}
+	}
+}
+
+// combinations returns every string made of n elements of the alphabet.
+func combinations(alphabet []string, n int) []string {
+	if n == 0 {
+		return []string{""}
+	}
+	var out []string
+	for _, prefix := range combinations(alphabet, n-1) {
+		for _, s := range alphabet {
+			out = append(out, prefix+s)
+		}
+	}
+	return out
+}
+
+// Tests that the Writer in strict mode escapes values as expected.
+func TestWriter_Write_Strict(t *testing.T) {
+	type test struct {
+		Value, Comment string
+		Expected       string
+	}
+
+	tests := []test{
+		{"a", "", "a\n"},
+		{"a#b\\c", "", "a\\#b\\\\c\n"},
+		{"a\"b", "", "a\"b\n"},
+		{"\"a", "", "\"\\\"a\"\n"},
+		{" a#\\\"", "", "\" a#\\\\\\\"\"\n"},
+		{"a\\", "c", "a\\\\#c\n"},
+	}
+
+	for _, tt := range tests {
+		var buff bytes.Buffer
+		w := NewCustomWriter(&buff, strictParameters())
+		w.LeadingCommentSpace, w.TrailingCommentSpace = "", ""
+		if err := w.WriteComment(tt.Value, tt.Comment); err != nil {
+			t.Errorf("Failed to write %q: %+v", tt.Value, err)
+		}
+		w.Flush()
+
+		if buff.String() != tt.Expected {
+			t.Errorf("Unexpected output for %q.\nexpected: %q\nreceived: %q",
+				tt.Value, tt.Expected, buff.String())
+		}
+	}
+}
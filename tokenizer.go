@@ -0,0 +1,178 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// lineReader is the source of the lines tokenized by a Reader. Both Reader and
+// SplitParams read values with the same state machine in Reader.scanRecord
+// and only differ in where the lines come from.
+type lineReader interface {
+	// readLine returns the next line with its line ending, if it has one. It
+	// returns io.EOF, and no bytes, once there are no lines left.
+	readLine() (string, error)
+
+	// temporary reports whether each line shares memory that is reused by the
+	// next call to readLine, so that any part of it that is kept must be
+	// copied.
+	temporary() bool
+}
+
+// bufferedLines reads lines from an io.Reader into a buffer that is reused for
+// the whole input. The lines are strings that share the memory of the buffer,
+// so reading a line does not allocate or copy, but a line is only valid until
+// the next call to readLine.
+type bufferedLines struct {
+	r   io.Reader
+	buf []byte
+
+	// buf[start:end] is the unread part of the input read so far and err is
+	// the error returned by r, which is returned once it is empty.
+	start, end int
+	err        error
+}
+
+// Size of the buffer used by bufferedLines and the number of reads without
+// data or an error before giving up.
+const (
+	defaultBufSize = 4096
+	maxEmptyReads  = 100
+)
+
+// newBufferedLines returns a lineReader that reads from r.
+func newBufferedLines(r io.Reader) *bufferedLines {
+	return &bufferedLines{r: r}
+}
+
+// readLine returns the next line, reading more of the input until the line is
+// complete.
+func (b *bufferedLines) readLine() (string, error) {
+	// Number of unread bytes already searched for a newline
+	var searched int
+	for {
+		unread := b.buf[b.start:b.end]
+		if i := bytes.IndexByte(unread[searched:], '\n'); i > -1 {
+			b.start += searched + i + 1
+			return bytesString(unread[:searched+i+1]), nil
+		} else if b.err != nil {
+			// The last line may not have a line ending
+			b.start = b.end
+			if len(unread) == 0 {
+				return "", b.err
+			}
+			return bytesString(unread), nil
+		}
+
+		searched = len(unread)
+		b.fill()
+	}
+}
+
+// temporary returns true, since the buffer is overwritten by later reads.
+func (b *bufferedLines) temporary() bool { return true }
+
+// fill moves the unread bytes to the start of the buffer and reads the next
+// part of the input after them. The buffer doubles in size when the unread
+// bytes fill it, so that long lines are read in a number of steps that is
+// logarithmic in their length.
+func (b *bufferedLines) fill() {
+	n := copy(b.buf, b.buf[b.start:b.end])
+	b.start, b.end = 0, n
+	if n == len(b.buf) {
+		buf := make([]byte, 2*len(b.buf)+defaultBufSize)
+		copy(buf, b.buf[:n])
+		b.buf = buf
+	}
+
+	for i := 0; i < maxEmptyReads; i++ {
+		m, err := b.r.Read(b.buf[b.end:])
+		b.end += m
+		if err != nil {
+			b.err = err
+			return
+		} else if m > 0 {
+			return
+		}
+	}
+	b.err = io.ErrNoProgress
+}
+
+// bytesString returns a string that shares the memory of b. The bytes must not
+// be modified while the string is in use.
+func bytesString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+// stringLines reads lines from a string. The lines are slices of the string,
+// so nothing is copied.
+type stringLines struct {
+	s string
+}
+
+// temporary returns false, since the lines are slices of the string.
+func (sl *stringLines) temporary() bool { return false }
+
+// readLine returns the next line of the string.
+func (sl *stringLines) readLine() (string, error) {
+	if sl.s == "" {
+		return "", io.EOF
+	}
+
+	i := strings.IndexByte(sl.s, '\n') + 1
+	if i == 0 {
+		i = len(sl.s)
+	}
+	line := sl.s[:i]
+	sl.s = sl.s[i:]
+	return line, nil
+}
+
+// trimLeftSpace returns s without its leading whitespace. ASCII whitespace is
+// trimmed byte by byte and the slower Unicode check is only used once a
+// non-ASCII byte is reached.
+func trimLeftSpace(s string) string {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			return strings.TrimLeftFunc(s[i:], unicode.IsSpace)
+		} else if asciiSpace[c] == 0 {
+			return s[i:]
+		}
+	}
+	return ""
+}
+
+// trimRightSpace returns s without its trailing whitespace. Like trimLeftSpace,
+// it only decodes runes once a non-ASCII byte is reached.
+func trimRightSpace(s string) string {
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			return strings.TrimRightFunc(s[:i+1], unicode.IsSpace)
+		} else if asciiSpace[c] == 0 {
+			return s[:i+1]
+		}
+	}
+	return ""
+}
+
+// trimSpace returns s without its leading and trailing whitespace.
+func trimSpace(s string) string {
+	return trimRightSpace(trimLeftSpace(s))
+}
+
+// asciiSpace marks the ASCII characters for which unicode.IsSpace is true.
+var asciiSpace = [utf8.RuneSelf]uint8{
+	'\t': 1, '\n': 1, '\v': 1, '\f': 1, '\r': 1, ' ': 1}
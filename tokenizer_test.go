@@ -0,0 +1,159 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tests that Reader.ReadAll and SplitParams return the same values and errors
+// for every combination of special characters in both the lenient and strict
+// grammar.
+func TestSplitParams_ReadAll_Parity(t *testing.T) {
+	alphabet := []string{"a", " ", "\n", "\r\n", "\"", "\\", "#", "é", "\xff"}
+	inputs := []string{""}
+	for n := 1; n <= 4; n++ {
+		inputs = append(inputs, combinations(alphabet, n)...)
+	}
+
+	for _, strict := range []bool{false, true} {
+		p := DefaultParameters()
+		p.Strict = strict
+		for _, input := range inputs {
+			read, readErr := NewCustomReader(
+				strings.NewReader(input), p).ReadAll()
+			split, splitErr := SplitParams(input, p)
+			if !reflect.DeepEqual(read, split) ||
+				!reflect.DeepEqual(readErr, splitErr) {
+				t.Errorf("Different results for %q (strict %t)."+
+					"\nReadAll:     %q, %v\nSplitParams: %q, %v",
+					input, strict, read, readErr, split, splitErr)
+			}
+		}
+	}
+}
+
+// Tests that a line longer than the read buffer is read whole.
+func TestReader_ReadAll_LongLine(t *testing.T) {
+	long := strings.Repeat("a", 3*4096+17)
+	input := "b\n" + long + " # c\n\"" + long + "\n" + long + "\"\n"
+	expected := []string{"b", long, long + "\n" + long}
+
+	values, err := NewReader(strings.NewReader(input)).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read: %+v", err)
+	}
+	if !reflect.DeepEqual(expected, values) {
+		t.Errorf("Unexpected values.\nexpected: %d values\nreceived: %d values",
+			len(expected), len(values))
+	}
+}
+
+// Tests that bufferedLines and stringLines return the same lines.
+func Test_lineReader(t *testing.T) {
+	inputs := []string{"", "a", "a\n", "\n\n", "a\r\nb", "a\nb\n\nc",
+		strings.Repeat("x", 5000) + "\ny"}
+
+	for _, input := range inputs {
+		buffered := newBufferedLines(
+			iotest.HalfReader(strings.NewReader(input)))
+		str := &stringLines{input}
+		for {
+			bLine, bErr := buffered.readLine()
+			sLine, sErr := str.readLine()
+			if bLine != sLine || bErr != sErr {
+				t.Errorf("Different lines for %.20q.\nbuffered: %.20q, %v"+
+					"\nstring:   %.20q, %v", input, bLine, bErr, sLine, sErr)
+				break
+			} else if sErr == io.EOF {
+				break
+			}
+		}
+	}
+}
+
+// Tests that trimLeftSpace, trimRightSpace, and trimSpace trim the same
+// whitespace as the strings package.
+func Test_trimSpace(t *testing.T) {
+	alphabet := []string{"a", " ", "\t", "\n", "\v", " ", " ", "é",
+		"\x85", "\xff"}
+	inputs := []string{""}
+	for n := 1; n <= 4; n++ {
+		inputs = append(inputs, combinations(alphabet, n)...)
+	}
+
+	for _, s := range inputs {
+		if left, expected := trimLeftSpace(s),
+			strings.TrimLeftFunc(s, unicode.IsSpace); left != expected {
+			t.Errorf("Unexpected trimLeftSpace for %q."+
+				"\nexpected: %q\nreceived: %q", s, expected, left)
+		}
+		if right, expected := trimRightSpace(s),
+			strings.TrimRightFunc(s, unicode.IsSpace); right != expected {
+			t.Errorf("Unexpected trimRightSpace for %q."+
+				"\nexpected: %q\nreceived: %q", s, expected, right)
+		}
+		if trimmed, expected := trimSpace(s),
+			strings.TrimSpace(s); trimmed != expected {
+			t.Errorf("Unexpected trimSpace for %q."+
+				"\nexpected: %q\nreceived: %q", s, expected, trimmed)
+		}
+	}
+}
+
+// cutCommentRunes is the rune-by-rune implementation of
+// Parameters.cutComment, used to check the byte search.
+func cutCommentRunes(
+	p Parameters, line string, inRaw bool) (before, after string, found bool) {
+	var prev rune
+	for j, char := range line {
+		if p.isComment(char, prev) && !inRaw {
+			return line[:j], line[j+utf8.RuneLen(char):], true
+		} else if char == p.Raw && inRaw && !p.escapedAt(line, j) {
+			inRaw = false
+		}
+		prev = char
+	}
+	return line, "", false
+}
+
+// Tests that Parameters.cutComment returns the same results as decoding every
+// rune of the line, for both ASCII and multibyte special characters.
+func TestParameters_cutComment_Runes(t *testing.T) {
+	params := []Parameters{DefaultParameters(),
+		{Comment: '§', Raw: '«', Escape: '€'},
+		{Comment: '#', Raw: '"', Escape: '\\', EscapeSequences: true}}
+
+	for _, p := range params {
+		alphabet := []string{"a", " ", "\xe2", "\xff", string(p.Comment),
+			string(p.Raw), string(p.Escape)}
+		inputs := []string{""}
+		for n := 1; n <= 5; n++ {
+			inputs = append(inputs, combinations(alphabet, n)...)
+		}
+
+		for _, line := range inputs {
+			for _, inRaw := range []bool{false, true} {
+				before, after, found := p.cutComment(line, inRaw)
+				eBefore, eAfter, eFound := cutCommentRunes(p, line, inRaw)
+				if before != eBefore || after != eAfter || found != eFound {
+					t.Errorf("Unexpected result for %q (raw %t)."+
+						"\nexpected: %q, %q, %t\nreceived: %q, %q, %t",
+						line, inRaw, eBefore, eAfter, eFound,
+						before, after, found)
+				}
+			}
+		}
+	}
+}
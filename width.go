@@ -0,0 +1,116 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"unicode"
+)
+
+// tabWidth is the number of columns between tab stops.
+const tabWidth = 8
+
+// wideRanges contains the ranges of East Asian wide and fullwidth characters
+// that take up two columns in a terminal.
+var wideRanges = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x1100, 0x115F, 1}, // Hangul Jamo initial consonants
+		{0x231A, 0x231B, 1}, // Watch, hourglass
+		{0x2329, 0x232A, 1}, // Angle brackets
+		{0x23E9, 0x23EC, 1}, // Media control symbols
+		{0x23F0, 0x23F0, 1}, // Alarm clock
+		{0x23F3, 0x23F3, 1}, // Hourglass with flowing sand
+		{0x25FD, 0x25FE, 1}, // Medium small squares
+		{0x2614, 0x2615, 1}, // Umbrella, hot beverage
+		{0x2648, 0x2653, 1}, // Zodiac symbols
+		{0x267F, 0x267F, 1}, // Wheelchair symbol
+		{0x2693, 0x2693, 1}, // Anchor
+		{0x26A1, 0x26A1, 1}, // High voltage
+		{0x26AA, 0x26AB, 1}, // Medium circles
+		{0x26BD, 0x26BE, 1}, // Soccer ball, baseball
+		{0x26C4, 0x26C5, 1}, // Snowman, sun behind cloud
+		{0x26CE, 0x26CE, 1}, // Ophiuchus
+		{0x26D4, 0x26D4, 1}, // No entry
+		{0x26EA, 0x26EA, 1}, // Church
+		{0x26F2, 0x26F3, 1}, // Fountain, flag in hole
+		{0x26F5, 0x26F5, 1}, // Sailboat
+		{0x26FA, 0x26FA, 1}, // Tent
+		{0x26FD, 0x26FD, 1}, // Fuel pump
+		{0x2705, 0x2705, 1}, // Check mark
+		{0x270A, 0x270B, 1}, // Raised fists
+		{0x2728, 0x2728, 1}, // Sparkles
+		{0x274C, 0x274C, 1}, // Cross mark
+		{0x274E, 0x274E, 1}, // Negative squared cross mark
+		{0x2753, 0x2755, 1}, // Question and exclamation marks
+		{0x2757, 0x2757, 1}, // Heavy exclamation mark
+		{0x2795, 0x2797, 1}, // Heavy plus, minus, division
+		{0x27B0, 0x27B0, 1}, // Curly loop
+		{0x27BF, 0x27BF, 1}, // Double curly loop
+		{0x2B1B, 0x2B1C, 1}, // Large squares
+		{0x2B50, 0x2B50, 1}, // White medium star
+		{0x2B55, 0x2B55, 1}, // Heavy large circle
+		{0x2E80, 0x303E, 1}, // CJK radicals, Kangxi, CJK symbols
+		{0x3041, 0x33FF, 1}, // Hiragana, Katakana, Bopomofo, CJK compat
+		{0x3400, 0x4DBF, 1}, // CJK unified ideographs extension A
+		{0x4E00, 0x9FFF, 1}, // CJK unified ideographs
+		{0xA000, 0xA4CF, 1}, // Yi syllables and radicals
+		{0xA960, 0xA97F, 1}, // Hangul Jamo extended A
+		{0xAC00, 0xD7A3, 1}, // Hangul syllables
+		{0xF900, 0xFAFF, 1}, // CJK compatibility ideographs
+		{0xFE10, 0xFE19, 1}, // Vertical forms
+		{0xFE30, 0xFE6F, 1}, // CJK compatibility forms, small forms
+		{0xFF00, 0xFF60, 1}, // Fullwidth forms
+		{0xFFE0, 0xFFE6, 1}, // Fullwidth signs
+	},
+	R32: []unicode.Range32{
+		{0x16FE0, 0x16FE4, 1}, // Ideographic symbols
+		{0x17000, 0x18CFF, 1}, // Tangut
+		{0x1B000, 0x1B2FF, 1}, // Kana supplement and extensions
+		{0x1F004, 0x1F004, 1}, // Mahjong tile red dragon
+		{0x1F0CF, 0x1F0CF, 1}, // Playing card black joker
+		{0x1F18E, 0x1F18E, 1}, // Negative squared AB
+		{0x1F191, 0x1F19A, 1}, // Squared words
+		{0x1F200, 0x1F2FF, 1}, // Enclosed ideographic supplement
+		{0x1F300, 0x1F64F, 1}, // Miscellaneous symbols, emoticons
+		{0x1F680, 0x1F6FF, 1}, // Transport and map symbols
+		{0x1F7E0, 0x1F7EB, 1}, // Large coloured circles and squares
+		{0x1F90C, 0x1F9FF, 1}, // Supplemental symbols and pictographs
+		{0x1FA70, 0x1FAFF, 1}, // Symbols and pictographs extended A
+		{0x20000, 0x2FFFD, 1}, // CJK unified ideographs extensions B to F
+		{0x30000, 0x3FFFD, 1}, // CJK unified ideographs extension G
+	},
+}
+
+// runeWidth returns the number of columns the rune takes up in a terminal.
+// Control characters, combining marks, and format characters take up no
+// columns and East Asian wide and fullwidth characters take up two.
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.IsControl(r):
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case unicode.Is(wideRanges, r):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// stringWidth returns the number of columns the string takes up in a terminal
+// when starting at the first column. Tabs advance to the next tab stop.
+func stringWidth(s string) int {
+	var width int
+	for _, r := range s {
+		if r == '\t' {
+			width += tabWidth - width%tabWidth
+		} else {
+			width += runeWidth(r)
+		}
+	}
+	return width
+}
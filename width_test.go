@@ -0,0 +1,47 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright (c) 2022 jonow                                                   //
+//                                                                            //
+// Use of this source code is governed by an MIT-style license that can be    //
+// found in the LICENSE file.                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+package lsv
+
+import (
+	"testing"
+)
+
+// Tests that stringWidth returns the expected width for each test.
+func Test_stringWidth(t *testing.T) {
+	type test struct {
+		Name   string
+		Input  string
+		Output int
+	}
+
+	tests := []test{
+		{"Empty", "", 0},
+		{"ASCII", "value", 5},
+		{"Latin", "café", 4},
+		{"CombiningMark", "café", 4},
+		{"ZeroWidthJoiner", "a‍b", 2},
+		{"CJK", "日本語", 6},
+		{"Hangul", "한국어", 6},
+		{"Fullwidth", "ＡＢ", 4},
+		{"Emoji", "🍌", 2},
+		{"Mixed", "a日b", 4},
+		{"Tab", "a\tb", 9},
+		{"TabAtStop", "abcdefgh\tb", 17},
+		{"Control", "a\x00\x1bb", 2},
+		{"InvalidUTF8", "a\xffb", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if width := stringWidth(tt.Input); width != tt.Output {
+				t.Errorf("Unexpected width of %q.\nexpected: %d\nreceived: %d",
+					tt.Input, tt.Output, width)
+			}
+		})
+	}
+}
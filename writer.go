@@ -9,11 +9,20 @@ package lsv
 
 import (
 	"bufio"
+	"errors"
 	"io"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
+// ErrUnrepresentable is returned when a value cannot be written in a way that
+// is read back unchanged.
+var ErrUnrepresentable = errors.New("value cannot be represented in LSV")
+
+// ErrCommentNewline is returned when an inline comment contains a newline.
+var ErrCommentNewline = errors.New("inline comment contains a newline")
+
 const (
 	defaultLeadingCommentSpace  = "\t"
 	defaultTrailingCommentSpace = " "
@@ -45,7 +54,19 @@ type Writer struct {
 	// UseCRLF uses \r\n as the line terminator if set to true.
 	UseCRLF bool
 
+	// AlignComments aligns the inline comments of consecutive values that
+	// each have an inline comment. Values are padded with spaces to the width
+	// of the widest value in the block, measured in terminal columns, before
+	// LeadingCommentSpace is written. A block ends at a value without an
+	// inline comment, a multi-line value, a comment line, a blank line, or a
+	// call to [Writer.Flush].
+	AlignComments bool
+
 	w *bufio.Writer
+
+	// pending contains the encoded values and comments of the current block of
+	// aligned comments that have not been written yet.
+	pending []ValueComment
 }
 
 // NewWriter returns a new Writer that write to w.
@@ -59,6 +80,20 @@ func NewWriter(w io.Writer) *Writer {
 	}
 }
 
+// NewCustomWriter returns a new Writer that writes to w with custom LSV
+// parameters. All quoting and escaping is done using the Comment, Raw, and
+// Escape characters in the parameters so that the output can be read by a
+// [Reader] with the same parameters.
+func NewCustomWriter(w io.Writer, p Parameters) *Writer {
+	return &Writer{
+		Parameters:           p,
+		LeadingCommentSpace:  defaultLeadingCommentSpace,
+		TrailingCommentSpace: defaultTrailingCommentSpace,
+		UseCRLF:              false,
+		w:                    bufio.NewWriter(w),
+	}
+}
+
 // WriteAll writes multiple LSV records to w using [Writer.Write] and then calls
 // [Writer.Flush], returning any error from the [Writer.Flush].
 func (w *Writer) WriteAll(values []string) error {
@@ -72,11 +107,13 @@ func (w *Writer) WriteAll(values []string) error {
 			return err
 		}
 	}
-	return w.w.Flush()
+	return w.flush()
 }
 
 // Write writes a single LSV value to w along with any necessary quoting and
-// escaping.
+// escaping. Any value written successfully is read back unchanged by a [Reader]
+// with the same Parameters. If the value cannot be encoded that way, Write
+// returns ErrUnrepresentable and nothing is written.
 //
 // Writes are buffered, so [Writer.Flush] must eventually be called to ensure
 // that the record is written to the underlying [io.Writer].
@@ -107,13 +144,18 @@ func (w *Writer) WriteAllWithComments(values []ValueComment) error {
 			return err
 		}
 	}
-	return w.w.Flush()
+	return w.flush()
 }
 
 // WriteComment writes a single LSV record to w along with any necessary quoting
 // and escaping. If a comment is included, then it is appended to the end of the
 // value.
 //
+// If the value is empty, only the comment is written on its own line. Such a
+// comment may contain newlines, in which case it is written as a block comment
+// using [Writer.WriteBlockComment]. An inline comment cannot contain newlines
+// and WriteComment returns ErrCommentNewline if it does.
+//
 // Writes are buffered, so [Writer.Flush] must eventually be called to ensure
 // that the record is written to the underlying [io.Writer].
 func (w *Writer) WriteComment(value, comment string) error {
@@ -123,100 +165,245 @@ func (w *Writer) WriteComment(value, comment string) error {
 	return w.writeComment(value, comment)
 }
 
-// writeComment writes a single LSV record to w with an included comment,
-// if specified.
-func (w *Writer) writeComment(value, comment string) error {
-	var bytesWritten, n int
-	var err error
-
-	// If the value does not need to be escaped, then write the value to the
-	// buffer
-	if value != "" {
-		if !w.valueNeedsEscaping(value) {
-			n, err = strings.NewReplacer(
-				"\\#", "\\\\#", "#", "\\#").WriteString(w.w, value)
-			if err != nil {
-				return err
-			}
+// WriteAllRecords writes multiple records, including their comments, to w using
+// [Writer.WriteRecord] and then calls [Writer.Flush], returning any error from
+// the [Writer.Flush].
+func (w *Writer) WriteAllRecords(records []Record) error {
+	if !w.Verify() {
+		return ErrInvalidParams
+	}
 
-			bytesWritten += n
-		} else {
-			n, err = w.w.WriteRune('"')
-			if err != nil {
-				return err
-			}
-			bytesWritten += n
+	for _, rec := range records {
+		err := w.writeRecord(rec)
+		if err != nil {
+			return err
+		}
+	}
+	return w.flush()
+}
 
-			n, err = strings.NewReplacer(
-				"\\\"\n", "\\\\\"\n", "\"\n", "\\\"\n").WriteString(w.w, value)
-			if err != nil {
-				return err
-			}
-			bytesWritten += n
+// WriteRecord writes a single record to w. Each line of the block comment is
+// written on its own line above the value and the inline comment is appended
+// to the end of the value. If Quoted is true, then the value is written as a
+// raw string literal even if it does not need to be.
+//
+// Writes are buffered, so [Writer.Flush] must eventually be called to ensure
+// that the record is written to the underlying [io.Writer].
+func (w *Writer) WriteRecord(rec Record) error {
+	if !w.Verify() {
+		return ErrInvalidParams
+	}
+	return w.writeRecord(rec)
+}
 
-			n, err = w.w.WriteRune('"')
-			if err != nil {
-				return err
-			}
-			bytesWritten += n
+// writeRecord writes the block comment, value and inline comment of the record
+// to w.
+func (w *Writer) writeRecord(rec Record) error {
+	for _, line := range rec.BlockComment {
+		err := w.writeBlockComment(line)
+		if err != nil {
+			return err
 		}
 	}
+	return w.writeValue(rec.Value, rec.Comment, rec.Quoted)
+}
 
-	if comment != "" {
-		if bytesWritten > 0 {
-			n, err = w.w.WriteString(w.LeadingCommentSpace)
-			if err != nil {
-				return err
-			}
-			bytesWritten += n
-		}
-		n, err = w.w.WriteRune(w.Comment)
+// WriteBlockComment writes the text as a block comment. Each line of the text
+// is written on its own line prefixed with the Comment character. Empty lines
+// are written as a lone Comment character.
+//
+// Writes are buffered, so [Writer.Flush] must eventually be called to ensure
+// that the comment is written to the underlying [io.Writer].
+func (w *Writer) WriteBlockComment(text string) error {
+	if !w.Verify() {
+		return ErrInvalidParams
+	}
+	return w.writeBlockComment(text)
+}
+
+// writeBlockComment writes each line of the text as a comment line to w.
+func (w *Writer) writeBlockComment(text string) error {
+	for _, line := range strings.Split(text, "\n") {
+		err := w.writeCommentLine(strings.TrimSuffix(line, "\r"))
 		if err != nil {
 			return err
 		}
-		bytesWritten += n
+	}
+	return nil
+}
+
+// writeCommentLine writes a line containing only a comment to w. The comment
+// must not contain a newline. An empty comment is written as a lone Comment
+// character.
+func (w *Writer) writeCommentLine(comment string) error {
+	err := w.writeAligned()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.w.WriteRune(w.Comment)
+	if err != nil {
+		return err
+	}
 
-		n, err = w.w.WriteString(w.TrailingCommentSpace)
+	if comment != "" {
+		_, err = w.w.WriteString(w.TrailingCommentSpace)
 		if err != nil {
 			return err
 		}
-		bytesWritten += n
-
-		n, err = w.w.WriteString(comment)
+		_, err = w.w.WriteString(comment)
 		if err != nil {
 			return err
 		}
-		bytesWritten += n
 	}
 
-	if value == "" && comment == "" {
-		n, err = w.w.WriteRune(w.Raw)
+	return w.writeLineEnd()
+}
+
+// writeComment writes a single LSV record to w with an included comment,
+// if specified.
+func (w *Writer) writeComment(value, comment string) error {
+	return w.writeValue(value, comment, false)
+}
+
+// writeValue writes a single LSV record to w with an included comment, if
+// specified. If quote is true, the value is always written as a raw string
+// literal.
+func (w *Writer) writeValue(value, comment string, quote bool) error {
+	// A comment without a value is written on its own line and can span
+	// multiple lines, but an inline comment cannot
+	if value == "" && !quote && comment != "" {
+		return w.writeBlockComment(comment)
+	} else if strings.IndexByte(comment, '\n') > -1 {
+		return ErrCommentNewline
+	}
+
+	// The value is encoded before anything is written so that the output is
+	// untouched if the value cannot be represented. An empty value can only be
+	// written as a raw string literal.
+	encoded, err := w.encodeValue(value, quote || value == "")
+	if err != nil {
+		return err
+	}
+	return w.writeEncoded(encoded, comment)
+}
+
+// writeEncoded writes the encoded value and its inline comment, if specified,
+// on their own line. The comment cannot contain newlines.
+func (w *Writer) writeEncoded(encoded, comment string) error {
+	// An Escape character at the end of the value would escape the Comment
+	// character of an inline comment written directly after it. In strict
+	// mode, it is always part of an escape sequence.
+	if comment != "" && w.LeadingCommentSpace == "" && !w.Strict &&
+		lastRune(encoded) == w.Escape {
+		return ErrUnrepresentable
+	}
+
+	if w.AlignComments && comment != "" &&
+		strings.IndexByte(encoded, '\n') == -1 {
+		w.pending = append(w.pending, ValueComment{encoded, comment})
+		return nil
+	}
+
+	err := w.writeAligned()
+	if err != nil {
+		return err
+	}
+	return w.writeLine(encoded, "", comment)
+}
+
+// writeLine writes the encoded value followed by the padding and the comment,
+// if one is specified, and the line terminator to w.
+func (w *Writer) writeLine(encoded, padding, comment string) error {
+	_, err := w.w.WriteString(encoded)
+	if err != nil {
+		return err
+	}
+
+	if comment != "" {
+		_, err = w.w.WriteString(padding)
 		if err != nil {
 			return err
 		}
-		bytesWritten += n
-		n, err = w.w.WriteRune(w.Raw)
+		_, err = w.w.WriteString(w.LeadingCommentSpace)
 		if err != nil {
 			return err
 		}
-		bytesWritten += n
+		_, err = w.w.WriteRune(w.Comment)
+		if err != nil {
+			return err
+		}
+		_, err = w.w.WriteString(w.TrailingCommentSpace)
+		if err != nil {
+			return err
+		}
+		_, err = w.w.WriteString(comment)
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.writeLineEnd()
+}
+
+// writeAligned writes the pending block of values with their comments aligned
+// to the same column.
+func (w *Writer) writeAligned() error {
+	var width int
+	for _, line := range w.pending {
+		if n := stringWidth(line.Value); n > width {
+			width = n
+		}
 	}
 
-	// Do not add delimiter if no value was written
-	if bytesWritten > 0 {
-		if w.UseCRLF {
-			_, err = w.w.WriteString("\r\n")
-		} else {
-			err = w.w.WriteByte('\n')
+	for _, line := range w.pending {
+		padding := strings.Repeat(" ", width-stringWidth(line.Value))
+		err := w.writeLine(line.Value, padding, line.Comment)
+		if err != nil {
+			return err
 		}
 	}
-	return err
+	w.pending = w.pending[:0]
+
+	return nil
+}
+
+// WriteBlankLine writes an empty line to w. When AlignComments is set, a blank
+// line ends the current block of aligned comments.
+//
+// Writes are buffered, so [Writer.Flush] must eventually be called to ensure
+// that the line is written to the underlying [io.Writer].
+func (w *Writer) WriteBlankLine() error {
+	err := w.writeAligned()
+	if err != nil {
+		return err
+	}
+	return w.writeLineEnd()
+}
+
+// writeLineEnd writes the line terminator to w.
+func (w *Writer) writeLineEnd() error {
+	if w.UseCRLF {
+		_, err := w.w.WriteString("\r\n")
+		return err
+	}
+	return w.w.WriteByte('\n')
 }
 
 // Flush writes any buffered data to the underlying [io.Writer]. To check if an
 // error occurred during the [Writer.Flush], call [Writer.Error].
 func (w *Writer) Flush() {
-	_ = w.w.Flush()
+	_ = w.flush()
+}
+
+// flush writes any pending aligned values and buffered data to the underlying
+// io.Writer.
+func (w *Writer) flush() error {
+	err := w.writeAligned()
+	if err != nil {
+		return err
+	}
+	return w.w.Flush()
 }
 
 // Error reports any error that has occurred during a previous [Writer.Write] or
@@ -226,6 +413,81 @@ func (w *Writer) Error() error {
 	return err
 }
 
+// commentEscaper returns a replacer that escapes every Comment character in an
+// unquoted value. A Comment character that is already preceded by the Escape
+// character is escaped again so that the Escape character is kept when read.
+func (w *Writer) commentEscaper() *strings.Replacer {
+	esc, comment := string(w.Escape), string(w.Comment)
+	return strings.NewReplacer(
+		esc+comment, esc+esc+comment, comment, esc+comment)
+}
+
+// encodeValue returns the value as it is written to the LSV, with any necessary
+// quoting and escaping. If quote is true, the value is always written as a raw
+// string literal. It returns ErrUnrepresentable if the value cannot be written
+// in a way that is read back unchanged. If EscapeSequences is set, values with
+// control characters or invalid UTF-8 are quoted and every quoted value can be
+// written.
+func (w *Writer) encodeValue(value string, quote bool) (string, error) {
+	if w.Strict {
+		return w.encodeStrictValue(value, quote), nil
+	}
+
+	// A value that starts with an escaped opening section character cannot
+	// be written unquoted since the Escape character is removed when read
+	escapedSection := string(w.Escape) + string(sectionOpen)
+	if !quote && !w.valueNeedsEscaping(value) &&
+		!(w.EscapeSequences && needsSequence(value)) &&
+		!(w.Sections && strings.HasPrefix(value, escapedSection)) {
+		return w.escapeSection(w.commentEscaper().Replace(value)), nil
+	} else if w.EscapeSequences {
+		raw := string(w.Raw)
+		return raw + w.encodeSequences(value) + raw, nil
+	}
+
+	quoted, err := w.quoteValue(value)
+	if err != nil {
+		return "", err
+	}
+	return string(w.Raw) + quoted + string(w.Raw), nil
+}
+
+// quoteValue escapes the value so that it can be placed between the opening and
+// closing Raw characters of a raw string literal.
+//
+// Inside a raw string literal, only a Raw character that is the last
+// non-whitespace character on a line can be escaped. Every other character is
+// read literally. quoteValue returns ErrUnrepresentable if the value ends in
+// the Escape character, which would escape the closing Raw character, or if a
+// line contains an unescaped Raw character followed by a Comment character,
+// which would be read as a comment.
+func (w *Writer) quoteValue(value string) (string, error) {
+	if lastRune(value) == w.Escape {
+		return "", ErrUnrepresentable
+	}
+
+	lines := strings.Split(value, "\n")
+	for i, line := range lines {
+		// Escape a Raw character at the end of every line except the last so
+		// that it is not read as the closing Raw character
+		if i < len(lines)-1 {
+			trimmed := strings.TrimRightFunc(line, unicode.IsSpace)
+			c, size := utf8.DecodeLastRuneInString(trimmed)
+			if c == w.Raw {
+				j := len(trimmed) - size
+				line = line[:j] + string(w.Escape) + line[j:]
+				lines[i] = line
+			}
+		}
+
+		if _, _, found := w.cutComment(line, true); found {
+			return "", ErrUnrepresentable
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
 // valueNeedsEscaping determines if the value needs to be escaped. Values with
 // leading/trailing whitespace, newlines, or quotes at end of lines need to be
 // escaped.
@@ -156,6 +156,36 @@ e
 "
 b
 `,
+}, {
+	Name:   "QuoteBeforeTrailingSpace",
+	Input:  []string{" a\" \nb", "c\"\r\nd "},
+	Output: "\" a\\\" \nb\"\n\"c\\\"\r\nd \"\n",
+}, {
+	Name:   "EscapedQuoteBeforeTrailingSpace",
+	Input:  []string{" a\\\"\t\nb"},
+	Output: "\" a\\\\\"\t\nb\"\n",
+}, {
+	Name:    "CustomComment",
+	Input:   []string{"a;b", `a\;b`, "c#d"},
+	Output:  "a\\;b\na\\\\;b\nc#d\n",
+	Comment: ';',
+}, {
+	Name:   "CustomRaw",
+	Input:  []string{" a", "b'\nc", `"d"`, "'e"},
+	Output: "' a'\n'b\\'\nc'\n\"d\"\n''e'\n",
+	Raw:    '\'',
+}, {
+	Name:   "CustomEscape",
+	Input:  []string{"a#b", "c!#d", " e\"\nf"},
+	Output: "a!#b\nc!!#d\n\" e!\"\nf\"\n",
+	Escape: '!',
+}, {
+	Name:    "CustomAll",
+	Input:   []string{"a;b", " c'\nd", "e"},
+	Output:  "a%;b\n' c%'\nd'\ne\n",
+	Comment: ';',
+	Raw:     '\'',
+	Escape:  '%',
 }, {
 	Name:  "BadRaw_IsSpace",
 	Raw:   '\r',
@@ -285,6 +315,14 @@ var writeCommentTests = []writeCommentTest{{
 	Name:   "SingeLineComment",
 	Input:  []ValueComment{{"", "Comment"}},
 	Output: "# Comment\n",
+}, {
+	Name:   "MultiLineComment",
+	Input:  []ValueComment{{"", "line1\nline2"}, {"a", ""}},
+	Output: "# line1\n# line2\na\n",
+}, {
+	Name:   "MultiLineCommentCRLF",
+	Input:  []ValueComment{{"", "line1\r\n\r\nline2\r\n"}},
+	Output: "# line1\n#\n# line2\n#\n",
 }, {
 	Name:   "EscapedSingeLineComment",
 	Input:  []ValueComment{{"# Comment", ""}},
@@ -437,6 +475,68 @@ func TestNewWriter_Consistency(t *testing.T) {
 	}
 }
 
+// Tests that NewCustomWriter returns a pointer to a new Writer with the
+// expected values.
+func TestNewCustomWriter(t *testing.T) {
+	buff := bytes.NewBufferString("")
+
+	expected := &Writer{
+		Parameters: Parameters{
+			Comment:          ';',
+			Raw:              '\'',
+			Escape:           '%',
+			TrimLeadingSpace: false,
+		},
+		LeadingCommentSpace:  defaultLeadingCommentSpace,
+		TrailingCommentSpace: defaultTrailingCommentSpace,
+		UseCRLF:              false,
+		w:                    bufio.NewWriter(buff),
+	}
+
+	newWriter := NewCustomWriter(buff, expected.Parameters)
+
+	if !reflect.DeepEqual(expected, newWriter) {
+		t.Errorf("NewCustomWriter did not return the expected writer."+
+			"\nexpected: %+v\nreceived: %+v", expected, newWriter)
+	}
+}
+
+// Tests that values written by a Writer from NewCustomWriter are read back
+// unchanged by a Reader with the same Parameters for several dialects.
+func TestNewCustomWriter_RoundTrip(t *testing.T) {
+	dialects := []Parameters{
+		DefaultParameters(),
+		{Comment: ';', Raw: '"', Escape: '\\', TrimLeadingSpace: true},
+		{Comment: '#', Raw: '\'', Escape: '\\', TrimLeadingSpace: true},
+		{Comment: '#', Raw: '"', Escape: '!', TrimLeadingSpace: true},
+		{Comment: ';', Raw: '\'', Escape: '%', TrimLeadingSpace: false},
+		{Comment: 'θ', Raw: '«', Escape: 'λ', TrimLeadingSpace: true},
+	}
+
+	values := []string{"a", "  b", "c  ", "", "d\ne", "#f", ";g", "h#i", "j;k",
+		`l\#m`, "n!#o", "p%;q", `"r"`, "'s'", "t\"\nu", "v'\nw", "x\\\"\ny",
+		"z!\"\na", "b%'\nc", "dθe", "fλθg", "«h", "i«\nj", "kλ«\nl"}
+
+	for _, p := range dialects {
+		t.Run(string([]rune{p.Comment, p.Raw, p.Escape}), func(t *testing.T) {
+			var buff bytes.Buffer
+			if err := NewCustomWriter(&buff, p).WriteAll(values); err != nil {
+				t.Fatalf("Failed to write values: %+v", err)
+			}
+
+			out, err := NewCustomReader(&buff, p).ReadAll()
+			if err != nil {
+				t.Fatalf("Failed to read values: %+v", err)
+			}
+
+			if !reflect.DeepEqual(values, out) {
+				t.Errorf("Values changed after round trip."+
+					"\nexpected: %q\nreceived: %q", values, out)
+			}
+		})
+	}
+}
+
 // Tests that Writer.WriteAll returns the expected error or value for each test.
 func TestWriter_WriteAll(t *testing.T) {
 	newWriter := func(tt writeTest, buff io.Writer) *Writer {
@@ -642,6 +742,219 @@ func TestWriter_WriteComment(t *testing.T) {
 	}
 }
 
+// Tests that Writer.Write returns ErrUnrepresentable and writes nothing for
+// values that cannot be read back unchanged.
+func TestWriter_Write_Unrepresentable(t *testing.T) {
+	values := []string{" a\\", "a\n\\", "\"a#b\nc", " a\n\"b #c "}
+
+	for _, value := range values {
+		var buff bytes.Buffer
+		w := NewWriter(&buff)
+		if err := w.Write(value); !errors.Is(err, ErrUnrepresentable) {
+			t.Errorf("Expected ErrUnrepresentable for %q, received %v.",
+				value, err)
+		}
+
+		w.Flush()
+		if buff.Len() != 0 {
+			t.Errorf("Unexpected output for %q: %q", value, buff.String())
+		}
+	}
+}
+
+// Tests that Writer.WriteComment returns ErrUnrepresentable when a value ending
+// in the Escape character is directly followed by a comment.
+func TestWriter_WriteComment_Unrepresentable(t *testing.T) {
+	var buff bytes.Buffer
+	w := NewWriter(&buff)
+	w.LeadingCommentSpace = ""
+
+	if err := w.WriteComment("a", "comment"); err != nil {
+		t.Errorf("Unexpected error: %+v", err)
+	}
+	err := w.WriteComment(`a\`, "comment")
+	if !errors.Is(err, ErrUnrepresentable) {
+		t.Errorf("Expected ErrUnrepresentable, received %v.", err)
+	}
+
+	w.Flush()
+	if expected := "a# comment\n"; buff.String() != expected {
+		t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+			expected, buff.String())
+	}
+}
+
+// Tests that Writer.WriteBlockComment writes each line of the text as a
+// separate comment line that is read back as the block comment of the next
+// value.
+func TestWriter_WriteBlockComment(t *testing.T) {
+	type test struct {
+		Name   string
+		Input  string
+		Output string
+		Lines  []string
+	}
+
+	tests := []test{
+		{"Single", "Comment", "# Comment\n", []string{"Comment"}},
+		{"Empty", "", "#\n", []string{""}},
+		{"MultiLine", "line1\nline2\n\nline4", "# line1\n# line2\n#\n# line4\n",
+			[]string{"line1", "line2", "", "line4"}},
+		{"CRLF", "line1\r\nline2", "# line1\n# line2\n",
+			[]string{"line1", "line2"}},
+		{"LooksLikeValue", "#\n\"a\"", "# #\n# \"a\"\n", []string{"#", `"a"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var buff bytes.Buffer
+			w := NewWriter(&buff)
+			if err := w.WriteBlockComment(tt.Input); err != nil {
+				t.Fatalf("Unexpected WriteBlockComment error: %+v", err)
+			}
+			w.Flush()
+
+			if buff.String() != tt.Output {
+				t.Errorf("WriteBlockComment unexpected output:"+
+					"\nexpected: %q\nreceived: %q", tt.Output, buff.String())
+			}
+
+			buff.WriteString("value\n")
+			records, err := NewReader(&buff).ReadAllRecords()
+			if err != nil {
+				t.Fatalf("Failed to read records: %+v", err)
+			}
+			if len(records) != 1 ||
+				!reflect.DeepEqual(records[0].BlockComment, tt.Lines) {
+				t.Errorf("Unexpected records read back."+
+					"\nexpected: %q\nreceived: %+v", tt.Lines, records)
+			}
+		})
+	}
+}
+
+// Tests that Writer.WriteComment returns ErrCommentNewline for an inline
+// comment containing a newline and writes nothing.
+func TestWriter_WriteComment_Newline(t *testing.T) {
+	var buff bytes.Buffer
+	w := NewWriter(&buff)
+
+	for _, vc := range []ValueComment{{"a", "b\nc"}, {" a", "\n"}} {
+		err := w.WriteComment(vc.Value, vc.Comment)
+		if !errors.Is(err, ErrCommentNewline) {
+			t.Errorf("Expected ErrCommentNewline for %+v, received %v.",
+				vc, err)
+		}
+	}
+
+	err := w.WriteRecord(Record{ValueComment{"", "b\nc"}, true, nil})
+	if !errors.Is(err, ErrCommentNewline) {
+		t.Errorf("Expected ErrCommentNewline for quoted value, received %v.",
+			err)
+	}
+
+	w.Flush()
+	if buff.Len() != 0 {
+		t.Errorf("Unexpected output: %q", buff.String())
+	}
+}
+
+// Tests that inline comments are aligned when Writer.AlignComments is set.
+func TestWriter_AlignComments(t *testing.T) {
+	type test struct {
+		Name   string
+		Write  func(w *Writer) error
+		Output string
+	}
+
+	tests := []test{{
+		Name: "Block",
+		Write: func(w *Writer) error {
+			return w.WriteAllWithComments([]ValueComment{
+				{"a", "1"}, {"bbb", "2"}, {" cc", "3"}})
+		},
+		Output: "a    \t# 1\nbbb  \t# 2\n\" cc\"\t# 3\n",
+	}, {
+		Name: "UnicodeWidth",
+		Write: func(w *Writer) error {
+			return w.WriteAllWithComments([]ValueComment{
+				{"日本", "1"}, {"cafe\u0301", "2"}, {"abcde", "3"}})
+		},
+		Output: "日本 \t# 1\ncafe\u0301 \t# 2\nabcde\t# 3\n",
+	}, {
+		Name: "BrokenByValueWithoutComment",
+		Write: func(w *Writer) error {
+			return w.WriteAllWithComments([]ValueComment{{"a", "1"},
+				{"bb", "2"}, {"ccc", ""}, {"dddd", "4"}, {"e", "5"}})
+		},
+		Output: "a \t# 1\nbb\t# 2\nccc\ndddd\t# 4\ne   \t# 5\n",
+	}, {
+		Name: "BrokenByMultiLineValue",
+		Write: func(w *Writer) error {
+			return w.WriteAllWithComments([]ValueComment{
+				{"a", "1"}, {"bb", "2"}, {"c\nc", "3"}, {"d", "4"}})
+		},
+		Output: "a \t# 1\nbb\t# 2\n\"c\nc\"\t# 3\nd\t# 4\n",
+	}, {
+		Name: "BrokenByBlockComment",
+		Write: func(w *Writer) error {
+			_ = w.WriteComment("a", "1")
+			_ = w.WriteComment("bb", "2")
+			_ = w.WriteBlockComment("Block")
+			_ = w.WriteComment("ccc", "3")
+			return w.WriteComment("d", "4")
+		},
+		Output: "a \t# 1\nbb\t# 2\n# Block\nccc\t# 3\nd  \t# 4\n",
+	}, {
+		Name: "BrokenByBlankLine",
+		Write: func(w *Writer) error {
+			_ = w.WriteComment("a", "1")
+			_ = w.WriteComment("bb", "2")
+			_ = w.WriteBlankLine()
+			_ = w.WriteComment("ccc", "3")
+			return w.WriteComment("d", "4")
+		},
+		Output: "a \t# 1\nbb\t# 2\n\nccc\t# 3\nd  \t# 4\n",
+	}, {
+		Name: "BrokenByFlush",
+		Write: func(w *Writer) error {
+			_ = w.WriteComment("a", "1")
+			w.Flush()
+			return w.WriteComment("bb", "2")
+		},
+		Output: "a\t# 1\nbb\t# 2\n",
+	},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var buff bytes.Buffer
+			w := NewWriter(&buff)
+			w.AlignComments = true
+
+			if err := tt.Write(w); err != nil {
+				t.Fatalf("Unexpected error: %+v", err)
+			}
+			w.Flush()
+
+			if buff.String() != tt.Output {
+				t.Errorf("Unexpected output.\nexpected: %q\nreceived: %q",
+					tt.Output, buff.String())
+			}
+
+			values, err := NewReader(&buff).ReadAll()
+			if err != nil {
+				t.Fatalf("Failed to read back aligned output: %+v", err)
+			}
+			for _, value := range values {
+				if value != strings.TrimRight(value, " ") {
+					t.Errorf("Padding read back as part of value %q.", value)
+				}
+			}
+		})
+	}
+}
+
 type errorWriter struct{}
 
 func (e errorWriter) Write([]byte) (int, error) {
@@ -773,3 +1086,80 @@ func Test_lastRune(t *testing.T) {
 		})
 	}
 }
+
+// Tests that Writer.WriteAllRecords writes the expected output for each test.
+func TestWriter_WriteAllRecords(t *testing.T) {
+	type test struct {
+		Name   string
+		Input  []Record
+		Output string
+	}
+
+	tests := []test{{
+		Name: "Simple",
+		Input: []Record{{ValueComment{"a", ""}, false, nil},
+			{ValueComment{"b", "Comment"}, false, nil}},
+		Output: "a\nb\t# Comment\n",
+	}, {
+		Name: "Quoted",
+		Input: []Record{{ValueComment{"a", ""}, true, nil},
+			{ValueComment{"", "Comment"}, true, nil},
+			{ValueComment{" b", ""}, true, nil}},
+		Output: "\"a\"\n\"\"\t# Comment\n\" b\"\n",
+	}, {
+		Name: "BlockComments",
+		Input: []Record{
+			{ValueComment{"a", ""}, false, []string{"Comment 1", "", "2"}},
+			{ValueComment{"b", "Comment 3"}, false, []string{"Comment 4"}}},
+		Output: "# Comment 1\n#\n# 2\na\n# Comment 4\nb\t# Comment 3\n",
+	},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var buff bytes.Buffer
+			w := NewWriter(&buff)
+			if err := w.WriteAllRecords(tt.Input); err != nil {
+				t.Fatalf("Unexpected WriteAllRecords error: %+v", err)
+			}
+
+			if buff.String() != tt.Output {
+				t.Errorf("WriteAllRecords unexpected output:"+
+					"\nexpected: %q\nreceived: %q", tt.Output, buff.String())
+			}
+		})
+	}
+}
+
+// Tests that records read by Reader.ReadAllRecords and written by
+// Writer.WriteAllRecords are read back unchanged.
+func TestWriter_WriteAllRecords_RoundTrip(t *testing.T) {
+	const input = `# Groceries
+bananas
+eggs	# large
+
+# Colours
+"  green"	# Indented
+#
+"red"
+`
+	records, err := NewReader(strings.NewReader(input)).ReadAllRecords()
+	if err != nil {
+		t.Fatalf("Failed to read records: %+v", err)
+	}
+
+	var buff bytes.Buffer
+	if err = NewWriter(&buff).WriteAllRecords(records); err != nil {
+		t.Fatalf("Failed to write records: %+v", err)
+	}
+
+	records2, err := NewReader(&buff).ReadAllRecords()
+	if err != nil {
+		t.Fatalf("Failed to read written records: %+v", err)
+	}
+
+	if !reflect.DeepEqual(records, records2) {
+		t.Errorf("Records changed after round trip."+
+			"\nexpected: %+v\nreceived: %+v", records, records2)
+	}
+}